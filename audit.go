@@ -0,0 +1,163 @@
+package zks
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bwesterb/go-ristretto"
+	mc "github.com/smarky7CD/go-dl-mercurial-commitments"
+)
+
+// An audit bundle opens the whole committed set: the members and the randomness of every node of the tree.
+//
+// Hard nodes are opened to their messages, soft nodes to the discrete logarithms of their two points.
+// A soft node opened this way can never be hard-opened without knowing log_g(h), so the prover can't hide
+// members below it, and the auditor learns the committed set exactly.
+//
+// The bundle reveals the full set and must only be handed to the auditor.
+type AuditBundle struct {
+	Max     uint64      `json:"max"`
+	Arity   uint64      `json:"arity"`
+	Depth   uint64      `json:"depth"`
+	Grown   []uint64    `json:"grown,omitempty"`
+	Members []uint64    `json:"members"`
+	Nodes   []AuditNode `json:"nodes"`
+}
+
+// A node of the tree in an audit bundle with the randomness opening it.
+type AuditNode struct {
+	Level uint64           `json:"level"`
+	Index uint64           `json:"index"`
+	Soft  bool             `json:"soft"`
+	Com   Com              `json:"commitment"`
+	R0    ristretto.Scalar `json:"r0"`
+	R1    ristretto.Scalar `json:"r1"`
+}
+
+// Opens the whole representation for an auditor.
+// Return: an audit bundle holding the sorted members and every node of the tree, root first.
+func (repr *Repr) OpenAll() *AuditBundle {
+	tree := &repr.tree
+	b := &AuditBundle{repr.max, tree.arity, tree.levels, tree.grown, tree.members(), nil}
+	for j := uint64(0); j <= tree.levels; j++ {
+		var indices []uint64
+		for i := range tree.tree[j] {
+			indices = append(indices, i)
+		}
+		slices.Sort(indices)
+		for _, i := range indices {
+			node := tree.tree[j][i]
+			b.Nodes = append(b.Nodes, AuditNode{j, i, node.soft, *node.com(), node.r0, node.r1})
+		}
+	}
+	return b
+}
+
+// Checks that the opening of a node in the bundle matches its commitment.
+// Hard nodes commit to the message recomputed from their children (all of which must be in the bundle),
+// leaves to their node ID at the depth of their region (see GrowUniverse), which must be a member.
+func (b *AuditBundle) checkNode(pp *PubVerPar, nodes map[uint64]map[uint64]*AuditNode, members map[uint64]bool, n *AuditNode) error {
+	if n.Soft {
+		c0, c1 := mc.SoftCommit(&n.R0, &n.R1)
+		if !n.Com.Equals(Com{c0, c1}) {
+			return fmt.Errorf("zks: audit: soft node %d at level %d does not open", n.Index, n.Level)
+		}
+		return nil
+	}
+
+	var msg []byte
+	if n.Level == b.Depth {
+		if !members[n.Index] {
+			return fmt.Errorf("zks: audit: hard leaf %d is not a listed member", n.Index)
+		}
+		level, _ := region(n.Index, n.Level, b.Depth, b.Arity, b.Grown)
+		msg = nodeID(n.Index, level)
+	} else {
+		coms := make([]*Com, b.Arity)
+		for k := range coms {
+			child, ok := nodes[n.Level+1][n.Index*b.Arity+uint64(k)]
+			if !ok {
+				return fmt.Errorf("zks: audit: node %d at level %d misses a child", n.Index, n.Level)
+			}
+			coms[k] = &child.Com
+		}
+		msg = childrenMessage(b.Arity, coms)
+	}
+
+	c0, c1 := mc.HardCommit(&pp.h, msg, &n.R0, &n.R1)
+	if !n.Com.Equals(Com{c0, c1}) {
+		return fmt.Errorf("zks: audit: hard node %d at level %d does not open", n.Index, n.Level)
+	}
+	return nil
+}
+
+// Input: The public parameters (h), the published ZKS commitment and an audit bundle.
+// Return: nil if the bundle opens exactly the commitment and the committed set is exactly the listed members,
+// an error describing the first discrepancy otherwise.
+func VerifyAudit(pp *PubVerPar, com Com, b *AuditBundle) error {
+	levels, arity, err := treeShape(b.Max, TreeOptions{Arity: b.Arity, Depth: b.Depth})
+	if err != nil {
+		return err
+	}
+	if levels != b.Depth || arity != b.Arity {
+		return fmt.Errorf("zks: audit: tree shape does not match the universe")
+	}
+	for i, depth := range b.Grown {
+		if depth >= levels || i > 0 && depth <= b.Grown[i-1] {
+			return fmt.Errorf("zks: audit: growth history does not match the tree")
+		}
+	}
+
+	members := make(map[uint64]bool)
+	for _, x := range b.Members {
+		if !inUniverse(x, b.Max) || !inCapacity(x, levels, arity) {
+			return fmt.Errorf("zks: audit: member %d is beyond the universe", x)
+		}
+		members[x] = true
+	}
+
+	nodes := make(map[uint64]map[uint64]*AuditNode)
+	for i := range b.Nodes {
+		n := &b.Nodes[i]
+		if n.Level > levels || !inCapacity(n.Index, n.Level, arity) {
+			return fmt.Errorf("zks: audit: node %d at level %d is outside the tree", n.Index, n.Level)
+		}
+		if nodes[n.Level] == nil {
+			nodes[n.Level] = make(map[uint64]*AuditNode)
+		}
+		if _, ok := nodes[n.Level][n.Index]; ok {
+			return fmt.Errorf("zks: audit: node %d at level %d is listed twice", n.Index, n.Level)
+		}
+		nodes[n.Level][n.Index] = n
+	}
+
+	root, ok := nodes[0][0]
+	if !ok || !root.Com.Equals(com) {
+		return fmt.Errorf("zks: audit: root does not match the commitment")
+	}
+	for _, n := range nodes[0] {
+		if err := b.checkNode(pp, nodes, members, n); err != nil {
+			return err
+		}
+	}
+	for j := uint64(1); j <= levels; j++ {
+		for _, n := range nodes[j] {
+			// every node hangs below a hard node
+			parent, ok := nodes[j-1][n.Index/arity]
+			if !ok || parent.Soft {
+				return fmt.Errorf("zks: audit: node %d at level %d is not below a hard node", n.Index, n.Level)
+			}
+			if err := b.checkNode(pp, nodes, members, n); err != nil {
+				return err
+			}
+		}
+	}
+
+	// every member has a hard leaf
+	for x := range members {
+		if leaf, ok := nodes[levels][x]; !ok || leaf.Soft {
+			return fmt.Errorf("zks: audit: member %d is not committed", x)
+		}
+	}
+	return nil
+}
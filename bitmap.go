@@ -0,0 +1,453 @@
+package zks
+
+import (
+	"encoding/binary"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// A compressed bitmap set (in the style of roaring bitmaps).
+//
+// The universe is split into chunks of 2^16 values keyed by their high 48 bits. Each non-empty chunk is stored
+// in a container holding either the sorted low 16 bits of its members (up to arrayMax members, 2 bytes each) or
+// a bitmap of all 2^16 values (8 KiB), whichever is smaller. Dense universes take about one bit per value and
+// sparse ones two bytes per member, where an EnumSet takes tens of bytes per stored value.
+//
+// Like an EnumSet, values at or beyond the maximum value are never members.
+type BitmapSet struct {
+	keys       []uint64
+	containers []*container
+	max        uint64
+}
+
+// The largest number of members of an array container; above it, a bitmap container is smaller.
+const arrayMax = 4096
+
+// The members of a chunk of 2^16 values: sorted low bits (array) or a bitmap of 1024 words (bits).
+// n is the number of members.
+type container struct {
+	array []uint16
+	bits  []uint64
+	n     int
+}
+
+// Creates an empty bitmap set of values below max.
+func NewBitmapSet(max uint64) *BitmapSet {
+	return &BitmapSet{max: max}
+}
+
+// Creates a bitmap set holding the members of a membership source.
+func NewBitmapSetFrom(src MembershipSource) *BitmapSet {
+	b := NewBitmapSet(src.Max())
+	for x := range src.Members() {
+		b.Add(x)
+	}
+	return b
+}
+
+func (c *container) contains(v uint16) bool {
+	if c.bits != nil {
+		return c.bits[v>>6]&(1<<(v&63)) != 0
+	}
+	_, ok := slices.BinarySearch(c.array, v)
+	return ok
+}
+
+func (c *container) add(v uint16) {
+	if c.bits != nil {
+		if c.bits[v>>6]&(1<<(v&63)) == 0 {
+			c.bits[v>>6] |= 1 << (v & 63)
+			c.n++
+		}
+		return
+	}
+	i, ok := slices.BinarySearch(c.array, v)
+	if ok {
+		return
+	}
+	c.array = slices.Insert(c.array, i, v)
+	c.n++
+	if c.n > arrayMax {
+		c.bits = c.words()
+		c.array = nil
+	}
+}
+
+func (c *container) remove(v uint16) {
+	if c.bits != nil {
+		if c.bits[v>>6]&(1<<(v&63)) != 0 {
+			c.bits[v>>6] &^= 1 << (v & 63)
+			c.n--
+		}
+		if c.n <= arrayMax {
+			*c = *containerOf(c.bits)
+		}
+		return
+	}
+	if i, ok := slices.BinarySearch(c.array, v); ok {
+		c.array = slices.Delete(c.array, i, i+1)
+		c.n--
+	}
+}
+
+// The members of the container as a bitmap, copied.
+func (c *container) words() []uint64 {
+	words := make([]uint64, 1024)
+	if c.bits != nil {
+		copy(words, c.bits)
+		return words
+	}
+	for _, v := range c.array {
+		words[v>>6] |= 1 << (v & 63)
+	}
+	return words
+}
+
+// Creates the smallest container holding the members of a bitmap, nil if it is empty.
+func containerOf(words []uint64) *container {
+	n := 0
+	for _, w := range words {
+		n += bits.OnesCount64(w)
+	}
+	switch {
+	case n == 0:
+		return nil
+	case n > arrayMax:
+		return &container{bits: words, n: n}
+	}
+	array := make([]uint16, 0, n)
+	for i, w := range words {
+		for w != 0 {
+			array = append(array, uint16(i<<6+bits.TrailingZeros64(w)))
+			w &= w - 1
+		}
+	}
+	return &container{array: array, n: n}
+}
+
+// Creates a container holding the sorted members of an array, nil if it is empty.
+func containerOfArray(array []uint16) *container {
+	if len(array) == 0 {
+		return nil
+	}
+	if len(array) > arrayMax {
+		return containerOf((&container{array: array}).words())
+	}
+	return &container{array: array, n: len(array)}
+}
+
+func (c *container) clone() *container {
+	return &container{slices.Clone(c.array), slices.Clone(c.bits), c.n}
+}
+
+// The largest member of a non-empty container.
+func (c *container) last() uint16 {
+	if c.bits == nil {
+		return c.array[len(c.array)-1]
+	}
+	i := len(c.bits) - 1
+	for c.bits[i] == 0 {
+		i--
+	}
+	return uint16(i<<6 + 63 - bits.LeadingZeros64(c.bits[i]))
+}
+
+// Iterates over the members of the container in ascending order.
+func (c *container) each(yield func(uint16) bool) bool {
+	if c.bits == nil {
+		for _, v := range c.array {
+			if !yield(v) {
+				return false
+			}
+		}
+		return true
+	}
+	for i, w := range c.bits {
+		for w != 0 {
+			if !yield(uint16(i<<6 + bits.TrailingZeros64(w))) {
+				return false
+			}
+			w &= w - 1
+		}
+	}
+	return true
+}
+
+// Finds the container of the chunk with the given key.
+func (b *BitmapSet) find(key uint64) (int, bool) {
+	return slices.BinarySearch(b.keys, key)
+}
+
+// Add a value x to the set if it is less than the maximum value.
+func (b *BitmapSet) Add(x uint64) {
+	if !inUniverse(x, b.max) {
+		return
+	}
+	i, ok := b.find(x >> 16)
+	if !ok {
+		b.keys = slices.Insert(b.keys, i, x>>16)
+		b.containers = slices.Insert(b.containers, i, &container{})
+	}
+	b.containers[i].add(uint16(x))
+}
+
+// Remove a value x from the set.
+func (b *BitmapSet) Remove(x uint64) {
+	i, ok := b.find(x >> 16)
+	if !ok {
+		return
+	}
+	b.containers[i].remove(uint16(x))
+	if b.containers[i].n == 0 {
+		b.keys = slices.Delete(b.keys, i, i+1)
+		b.containers = slices.Delete(b.containers, i, i+1)
+	}
+}
+
+// Respond true if x is in the set, false otherwise.
+func (b *BitmapSet) In(x uint64) bool {
+	i, ok := b.find(x >> 16)
+	return ok && b.containers[i].contains(uint16(x))
+}
+
+// The maximum value of the set: members are below it.
+func (b *BitmapSet) Max() uint64 {
+	return b.max
+}
+
+// The number of members of the set.
+func (b *BitmapSet) Len() uint64 {
+	var n uint64
+	for _, c := range b.containers {
+		n += uint64(c.n)
+	}
+	return n
+}
+
+// Iterates over the members of the set in ascending order.
+func (b *BitmapSet) Iterate() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		for i, c := range b.containers {
+			key := b.keys[i] << 16
+			if !c.each(func(v uint16) bool { return yield(key | uint64(v)) }) {
+				return
+			}
+		}
+	}
+}
+
+// Iterates over the members of the set in ascending order, as a MembershipSource.
+func (b *BitmapSet) Members() iter.Seq[uint64] {
+	return b.Iterate()
+}
+
+// Returns a deep copy of the set.
+func (b *BitmapSet) Clone() *BitmapSet {
+	c := &BitmapSet{slices.Clone(b.keys), make([]*container, len(b.containers)), b.max}
+	for i, ct := range b.containers {
+		c.containers[i] = ct.clone()
+	}
+	return c
+}
+
+// Combines the containers of two sets chunk by chunk.
+// op is called with the containers of every chunk present in either set (nil when absent) and returns the
+// container of the result, nil if it is empty.
+func (b *BitmapSet) combine(o *BitmapSet, max uint64, op func(x *container, y *container) *container) *BitmapSet {
+	r := NewBitmapSet(max)
+	emit := func(key uint64, c *container) {
+		if c != nil {
+			r.keys = append(r.keys, key)
+			r.containers = append(r.containers, c)
+		}
+	}
+	i, j := 0, 0
+	for i < len(b.keys) || j < len(o.keys) {
+		switch {
+		case j == len(o.keys) || (i < len(b.keys) && b.keys[i] < o.keys[j]):
+			emit(b.keys[i], op(b.containers[i], nil))
+			i++
+		case i == len(b.keys) || o.keys[j] < b.keys[i]:
+			emit(o.keys[j], op(nil, o.containers[j]))
+			j++
+		default:
+			emit(b.keys[i], op(b.containers[i], o.containers[j]))
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+// Applies a bitwise operation to the bitmaps of two containers.
+func wordsOp(x *container, y *container, op func(a uint64, b uint64) uint64) *container {
+	wx, wy := x.words(), y.words()
+	for k := range wx {
+		wx[k] = op(wx[k], wy[k])
+	}
+	return containerOf(wx)
+}
+
+// Returns the union of two sets, bounded by the larger maximum value.
+func (b *BitmapSet) Union(o *BitmapSet) *BitmapSet {
+	return b.combine(o, max(b.max, o.max), func(x *container, y *container) *container {
+		switch {
+		case x == nil:
+			return y.clone()
+		case y == nil:
+			return x.clone()
+		case x.bits == nil && y.bits == nil:
+			// merge the sorted arrays
+			array := make([]uint16, 0, x.n+y.n)
+			i, j := 0, 0
+			for i < len(x.array) || j < len(y.array) {
+				switch {
+				case j == len(y.array) || (i < len(x.array) && x.array[i] < y.array[j]):
+					array = append(array, x.array[i])
+					i++
+				case i == len(x.array) || y.array[j] < x.array[i]:
+					array = append(array, y.array[j])
+					j++
+				default:
+					array = append(array, x.array[i])
+					i++
+					j++
+				}
+			}
+			return containerOfArray(array)
+		}
+		return wordsOp(x, y, func(a uint64, b uint64) uint64 { return a | b })
+	})
+}
+
+// Returns the intersection of two sets, bounded by the smaller maximum value.
+func (b *BitmapSet) Intersect(o *BitmapSet) *BitmapSet {
+	return b.combine(o, min(b.max, o.max), func(x *container, y *container) *container {
+		switch {
+		case x == nil || y == nil:
+			return nil
+		case x.bits == nil:
+			return containerOfArray(slices.DeleteFunc(slices.Clone(x.array), func(v uint16) bool { return !y.contains(v) }))
+		case y.bits == nil:
+			return containerOfArray(slices.DeleteFunc(slices.Clone(y.array), func(v uint16) bool { return !x.contains(v) }))
+		}
+		return wordsOp(x, y, func(a uint64, b uint64) uint64 { return a & b })
+	})
+}
+
+// Returns the members of b that are not members of o, bounded by the maximum value of b.
+func (b *BitmapSet) Difference(o *BitmapSet) *BitmapSet {
+	return b.combine(o, b.max, func(x *container, y *container) *container {
+		switch {
+		case x == nil:
+			return nil
+		case y == nil:
+			return x.clone()
+		case x.bits == nil:
+			return containerOfArray(slices.DeleteFunc(slices.Clone(x.array), y.contains))
+		}
+		return wordsOp(x, y, func(a uint64, b uint64) uint64 { return a &^ b })
+	})
+}
+
+// Encodes the set: a version byte, the maximum value and the number of containers as uvarints, and every
+// container as its key (uvarint), a type byte and either the number of members (uvarint) followed by their
+// low bits (2 bytes each, little-endian) or the 1024 words of its bitmap (8 bytes each, little-endian).
+func (b *BitmapSet) MarshalBinary() ([]byte, error) {
+	buf := []byte{1}
+	buf = binary.AppendUvarint(buf, b.max)
+	buf = binary.AppendUvarint(buf, uint64(len(b.containers)))
+	for i, c := range b.containers {
+		buf = binary.AppendUvarint(buf, b.keys[i])
+		if c.bits == nil {
+			buf = append(buf, 0)
+			buf = binary.AppendUvarint(buf, uint64(c.n))
+			for _, v := range c.array {
+				buf = binary.LittleEndian.AppendUint16(buf, v)
+			}
+		} else {
+			buf = append(buf, 1)
+			for _, w := range c.bits {
+				buf = binary.LittleEndian.AppendUint64(buf, w)
+			}
+		}
+	}
+	return buf, nil
+}
+
+// Decodes a set.
+func (b *BitmapSet) UnmarshalBinary(data []byte) error {
+	uvarint := func() (uint64, bool) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, false
+		}
+		data = data[n:]
+		return v, true
+	}
+
+	if len(data) == 0 || data[0] != 1 {
+		return ErrMalformed
+	}
+	data = data[1:]
+	max, ok1 := uvarint()
+	count, ok2 := uvarint()
+	if !ok1 || !ok2 || count > uint64(len(data)) {
+		return ErrMalformed
+	}
+
+	v := NewBitmapSet(max)
+	for k := uint64(0); k < count; k++ {
+		key, ok := uvarint()
+		if !ok || len(data) == 0 || (k > 0 && key <= v.keys[k-1]) {
+			return ErrMalformed
+		}
+		kind := data[0]
+		data = data[1:]
+
+		var c *container
+		switch kind {
+		case 0:
+			n, ok := uvarint()
+			if !ok || n == 0 || n > arrayMax || uint64(len(data)) < 2*n {
+				return ErrMalformed
+			}
+			array := make([]uint16, n)
+			for i := range array {
+				array[i] = binary.LittleEndian.Uint16(data[2*i:])
+				if i > 0 && array[i] <= array[i-1] {
+					return ErrMalformed
+				}
+			}
+			data = data[2*n:]
+			c = &container{array: array, n: len(array)}
+		case 1:
+			if len(data) < 8192 {
+				return ErrMalformed
+			}
+			words := make([]uint64, 1024)
+			for i := range words {
+				words[i] = binary.LittleEndian.Uint64(data[8*i:])
+			}
+			data = data[8192:]
+			if c = containerOf(words); c == nil || c.bits == nil {
+				return ErrMalformed
+			}
+		default:
+			return ErrMalformed
+		}
+
+		// every member must be below the maximum value
+		if key>>48 != 0 || !inUniverse(key<<16|uint64(c.last()), max) {
+			return ErrMalformed
+		}
+		v.keys = append(v.keys, key)
+		v.containers = append(v.containers, c)
+	}
+	if len(data) != 0 {
+		return ErrMalformed
+	}
+	*b = *v
+	return nil
+}
@@ -0,0 +1,134 @@
+package zks
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Domain string of the signature on an offline bundle.
+const bundleDomain = "ZKS offline bundle v1"
+
+// ErrBundleExpired is returned when verifying an offline bundle past its expiry time.
+var ErrBundleExpired = errors.New("zks: bundle has expired")
+
+// ErrInvalidBundle is returned when an offline bundle is incomplete, doesn't hold the trusted verifier params
+// or one of its answers does not verify.
+var ErrInvalidBundle = errors.New("zks: invalid bundle")
+
+// An offline bundle holds everything needed to verify the answers for a list of elements without the prover:
+// the signed commitment, the verifier params and the answers, valid until an expiry time.
+//
+// The prover signs the bundle as a whole, so neither the expiry time nor the list of answers can be changed,
+// and every answer verifies under the signed commitment. The params are only carried along: a prover knowing
+// log_g(h) of params of its own choosing could prove anything, so verifiers check the bundle against params they trust.
+type Bundle struct {
+	Signed    *SignedCommitment `json:"signed"`
+	Params    *PubVerPar        `json:"params"`
+	Entries   []BundleEntry     `json:"entries"`
+	Expires   time.Time         `json:"expires"`
+	Signature []byte            `json:"signature"`
+}
+
+// An element of an offline bundle and the answer for it.
+type BundleEntry struct {
+	X      uint64  `json:"x"`
+	Answer *Answer `json:"answer"`
+}
+
+// Requirements on an offline bundle.
+// Params are the trusted verifier params the bundle must hold and is verified under, and are required.
+// Now is the time the expiry is checked against, the current time if zero.
+type BundleOptions struct {
+	Params *PubVerPar
+	Now    time.Time
+}
+
+// Encodes the signed fields of the bundle.
+func (b *Bundle) message() []byte {
+	digest := sha256.Sum256(b.Signed.message())
+
+	buf := binary.BigEndian.AppendUint64(nil, uint64(len(bundleDomain)))
+	buf = append(buf, bundleDomain...)
+	buf = append(buf, digest[:]...)
+	buf = append(buf, b.Params.h.Bytes()...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(b.Expires.UnixNano()))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(b.Entries)))
+	for _, e := range b.Entries {
+		answer, _ := e.Answer.MarshalBinary()
+		adigest := sha256.Sum256(answer)
+		buf = binary.BigEndian.AppendUint64(buf, e.X)
+		buf = append(buf, adigest[:]...)
+	}
+	return buf
+}
+
+// Input: The public parameters (h,ps), a ZKS representation, the private key of the prover, the signed commitment to the representation,
+// the elements to answer for and the time the bundle expires at.
+// Return: the signed offline bundle, or an error if pp holds no PRF key (ErrNoProverKey), the commitment is not the one of
+// the representation or an element is beyond the universe.
+func NewBundle(pp *PubVerPar, repr *Repr, priv ed25519.PrivateKey, sc *SignedCommitment, xs []uint64, expires time.Time) (*Bundle, error) {
+	if pp.kh == nil {
+		return nil, ErrNoProverKey
+	}
+	if !sc.Com.Equals(Com{repr.tree.root.c0, repr.tree.root.c1}) {
+		return nil, fmt.Errorf("%w: the signed commitment is not the commitment of the representation", ErrInvalidBundle)
+	}
+
+	b := &Bundle{Signed: sc, Params: &PubVerPar{h: pp.h}, Entries: []BundleEntry{}, Expires: expires.UTC().Round(0)}
+	for _, x := range xs {
+		a := Qry(pp, repr, x)
+		if a == nil {
+			return nil, fmt.Errorf("%w: %d is beyond the leaves of the tree", ErrInvalidBundle, x)
+		}
+		b.Entries = append(b.Entries, BundleEntry{x, a})
+	}
+	b.Signature = ed25519.Sign(priv, b.message())
+	return b, nil
+}
+
+// Input: the public key of the prover, an offline bundle and the requirements on it.
+// Return: the membership of every element of the bundle if the commitment and the bundle are signed by the prover,
+// the bundle has not expired and every answer verifies; an error describing the first failure otherwise.
+func VerifyBundle(pub ed25519.PublicKey, b *Bundle, opts BundleOptions) (map[uint64]bool, error) {
+	if opts.Params == nil {
+		return nil, fmt.Errorf("%w: no trusted verifier params", ErrInvalidBundle)
+	}
+	if b == nil || b.Signed == nil || b.Params == nil {
+		return nil, ErrInvalidBundle
+	}
+	if err := VerifySigned(pub, b.Signed); err != nil {
+		return nil, err
+	}
+	if !opts.Params.h.Equals(&b.Params.h) {
+		return nil, fmt.Errorf("%w: the bundle holds other verifier params", ErrInvalidBundle)
+	}
+	for _, e := range b.Entries {
+		if e.Answer == nil {
+			return nil, fmt.Errorf("%w: missing answer for %d", ErrInvalidBundle, e.X)
+		}
+	}
+	if !ed25519.Verify(pub, b.message(), b.Signature) {
+		return nil, ErrBadSignature
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if !now.Before(b.Expires) {
+		return nil, ErrBundleExpired
+	}
+
+	members := make(map[uint64]bool)
+	for _, e := range b.Entries {
+		if !VfySigned(opts.Params, pub, b.Signed, e.X, e.Answer) {
+			return nil, fmt.Errorf("%w: answer for %d does not verify", ErrInvalidBundle, e.X)
+		}
+		members[e.X] = e.Answer.Member()
+	}
+	return members, nil
+}
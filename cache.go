@@ -0,0 +1,183 @@
+package zks
+
+import (
+	"bytes"
+	"container/list"
+	"iter"
+	"sync"
+)
+
+// A proof cache answers queries on a static set by lookup.
+//
+// Precompute materialises the answers for all members up front. Answers for non-members are computed on
+// demand and kept in a bounded least-recently-used cache, which Warmup can fill ahead of the queries.
+// Answers are held in their binary encoding, which is served as is (QryBinary).
+// It is safe for concurrent use.
+type ProofCache struct {
+	pp      *PubVerPar
+	repr    *Repr
+	members map[uint64][]byte
+
+	mu      sync.Mutex
+	max     int
+	lru     *list.List
+	entries map[uint64]*list.Element
+	stats   CacheStats
+}
+
+// Options of a proof cache.
+// MaxNonMemberBytes bounds the size of the encoded non-member answers kept, zero keeps none.
+type CacheOptions struct {
+	MaxNonMemberBytes int
+}
+
+// Memory accounting and hit rates of a proof cache. Sizes are those of the encoded answers.
+type CacheStats struct {
+	Members        int
+	MemberBytes    int
+	NonMembers     int
+	NonMemberBytes int
+	Hits           uint64
+	Misses         uint64
+	Evictions      uint64
+}
+
+// A cached non-member answer.
+type cacheEntry struct {
+	x    uint64
+	data []byte
+}
+
+// Input: public parameters (h,ps), a ZKS representation and the options of the cache.
+// Return: a proof cache holding the answers for all members of the representation,
+// or an error if pp holds no PRF key (ErrNoProverKey) or an answer can't be encoded.
+func Precompute(pp *PubVerPar, repr *Repr, opts CacheOptions) (*ProofCache, error) {
+	if pp.kh == nil {
+		return nil, ErrNoProverKey
+	}
+
+	c := &ProofCache{
+		pp:      pp,
+		repr:    repr,
+		members: make(map[uint64][]byte),
+		max:     opts.MaxNonMemberBytes,
+		lru:     list.New(),
+		entries: make(map[uint64]*list.Element),
+	}
+	for _, x := range repr.tree.members() {
+		data, err := Qry(pp, repr, x).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		c.members[x] = data
+		c.stats.MemberBytes += len(data)
+	}
+	c.stats.Members = len(c.members)
+	return c, nil
+}
+
+// Input: an element x.
+// Return: the binary encoding of the answer for x (see Answer.MarshalBinary), nil if x is beyond the leaves of the tree.
+// The encoding is a copy the caller may modify.
+func (c *ProofCache) QryBinary(x uint64) []byte {
+	if data, ok := c.members[x]; ok {
+		c.mu.Lock()
+		c.stats.Hits++
+		c.mu.Unlock()
+		return bytes.Clone(data)
+	}
+
+	c.mu.Lock()
+	if e, ok := c.entries[x]; ok {
+		c.lru.MoveToFront(e)
+		c.stats.Hits++
+		c.mu.Unlock()
+		return bytes.Clone(e.Value.(*cacheEntry).data)
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	data := c.compute(x)
+	if data == nil {
+		return nil
+	}
+	c.add(x, data)
+	return bytes.Clone(data)
+}
+
+// Computes the binary encoding of the answer for x, nil if x is beyond the leaves of the tree.
+func (c *ProofCache) compute(x uint64) []byte {
+	data, err := Qry(c.pp, c.repr, x).MarshalBinary()
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// Input: an element x.
+// Return: Answer for x as returned by Qry, nil if x is beyond the leaves of the tree.
+func (c *ProofCache) Qry(x uint64) *Answer {
+	data := c.QryBinary(x)
+	if data == nil {
+		return nil
+	}
+	var a Answer
+	if err := a.UnmarshalBinary(data); err != nil {
+		return nil
+	}
+	return &a
+}
+
+// Keeps the non-member answer for x, evicting the least recently used answers beyond the bound.
+func (c *ProofCache) add(x uint64, data []byte) {
+	if len(data) > c.max {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[x]; ok {
+		return
+	}
+	c.entries[x] = c.lru.PushFront(&cacheEntry{x, data})
+	c.stats.NonMemberBytes += len(data)
+	for c.stats.NonMemberBytes > c.max {
+		e := c.lru.Back()
+		entry := c.lru.Remove(e).(*cacheEntry)
+		delete(c.entries, entry.x)
+		c.stats.NonMemberBytes -= len(entry.data)
+		c.stats.Evictions++
+	}
+	c.stats.NonMembers = len(c.entries)
+}
+
+// Computes the answers for the given elements ahead of their queries, e.g. the elements queried most.
+// Members are already cached; answers for non-members fill the cache up to its bound.
+// Return: the number of non-member answers computed.
+func (c *ProofCache) Warmup(xs iter.Seq[uint64]) int {
+	n := 0
+	for x := range xs {
+		if _, ok := c.members[x]; ok {
+			continue
+		}
+		c.mu.Lock()
+		_, ok := c.entries[x]
+		c.mu.Unlock()
+		if ok {
+			continue
+		}
+		data := c.compute(x)
+		if data == nil {
+			continue
+		}
+		c.add(x, data)
+		n++
+	}
+	return n
+}
+
+// The memory accounting and hit rates of the cache.
+func (c *ProofCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
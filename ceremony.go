@@ -0,0 +1,155 @@
+package zks
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/bwesterb/go-ristretto"
+)
+
+// A multi-party ceremony generating the commitment base h.
+//
+// The ceremony starts from the base point g. Every participant multiplies the current value by a
+// secret scalar they drew and publishes the result together with a Schnorr proof of knowledge of
+// that scalar. The final h is g multiplied by the product of all secrets, so log_g(h) stays unknown
+// as long as a single participant forgets their secret.
+//
+// The ceremony is its own transcript: any verifier can re-check every contribution with VerifyCeremony.
+type Ceremony struct {
+	Domain        string         `json:"domain"`
+	Contributions []Contribution `json:"contributions"`
+}
+
+// A contribution to a ceremony.
+// H is the value of h after the contribution.
+// R and Z form the proof of knowledge of the secret s with H = s*H' where H' is the previous value.
+type Contribution struct {
+	Participant string           `json:"participant"`
+	H           ristretto.Point  `json:"h"`
+	R           ristretto.Point  `json:"r"`
+	Z           ristretto.Scalar `json:"z"`
+}
+
+// ErrEmptyCeremony is returned when finalizing a ceremony nobody contributed to.
+var ErrEmptyCeremony = errors.New("zks: ceremony has no contributions")
+
+// Starts a new ceremony under a domain string.
+func NewCeremony(domain string) *Ceremony {
+	return &Ceremony{domain, []Contribution{}}
+}
+
+// The current value of h, g if nobody has contributed yet.
+func (c *Ceremony) Current() ristretto.Point {
+	var h ristretto.Point
+	if len(c.Contributions) == 0 {
+		h.SetBase()
+	} else {
+		h.Set(&c.Contributions[len(c.Contributions)-1].H)
+	}
+	return h
+}
+
+// Computes the challenge of the proof of knowledge for the i-th contribution.
+func ceremonyChallenge(domain string, i int, participant string, prev *ristretto.Point, next *ristretto.Point, r *ristretto.Point) ristretto.Scalar {
+	h := sha512.New()
+	for _, field := range [][]byte{[]byte(domain), []byte(participant)} {
+		bl := make([]byte, 8)
+		binary.BigEndian.PutUint64(bl, uint64(len(field)))
+		h.Write(bl)
+		h.Write(field)
+	}
+	bi := make([]byte, 8)
+	binary.BigEndian.PutUint64(bi, uint64(i))
+	h.Write(bi)
+	h.Write(prev.Bytes())
+	h.Write(next.Bytes())
+	h.Write(r.Bytes())
+
+	var e ristretto.Scalar
+	e.Derive(h.Sum(nil))
+	return e
+}
+
+// Contributes fresh randomness on behalf of a participant and appends the contribution.
+// The secret scalar is discarded once the contribution is made.
+func (c *Ceremony) Contribute(participant string) *Contribution {
+	prev := c.Current()
+
+	var s, k ristretto.Scalar
+	s.Rand()
+	k.Rand()
+
+	var next, r ristretto.Point
+	next.ScalarMult(&prev, &s)
+	r.ScalarMult(&prev, &k)
+
+	// z = k + e*s
+	e := ceremonyChallenge(c.Domain, len(c.Contributions), participant, &prev, &next, &r)
+	var z ristretto.Scalar
+	z.MulAdd(&e, &s, &k)
+
+	c.Contributions = append(c.Contributions, Contribution{participant, next, r, z})
+	return &c.Contributions[len(c.Contributions)-1]
+}
+
+// Verifies the i-th contribution of the ceremony given the value of h before it.
+func verifyContribution(domain string, i int, prev *ristretto.Point, contrib *Contribution) error {
+	var zero ristretto.Point
+	zero.SetZero()
+	if contrib.H.Equals(&zero) {
+		return fmt.Errorf("zks: contribution %d (%s) is the identity", i, contrib.Participant)
+	}
+
+	// check z*prev = r + e*h
+	e := ceremonyChallenge(domain, i, contrib.Participant, prev, &contrib.H, &contrib.R)
+	var lhs, rhs, eh ristretto.Point
+	lhs.ScalarMult(prev, &contrib.Z)
+	eh.ScalarMult(&contrib.H, &e)
+	rhs.Add(&contrib.R, &eh)
+	if !lhs.Equals(&rhs) {
+		return fmt.Errorf("zks: contribution %d (%s) has an invalid proof of knowledge", i, contrib.Participant)
+	}
+	return nil
+}
+
+// Verifies every contribution of a ceremony transcript.
+// Returns nil if the transcript is valid, an error naming the first invalid contribution otherwise.
+func VerifyCeremony(c *Ceremony) error {
+	var prev ristretto.Point
+	prev.SetBase()
+	for i := range c.Contributions {
+		if err := verifyContribution(c.Domain, i, &prev, &c.Contributions[i]); err != nil {
+			return err
+		}
+		prev.Set(&c.Contributions[i].H)
+	}
+	return nil
+}
+
+// Verifies the ceremony transcript and returns the resulting h.
+func (c *Ceremony) Finalize() (ristretto.Point, error) {
+	if len(c.Contributions) == 0 {
+		return ristretto.Point{}, ErrEmptyCeremony
+	}
+	if err := VerifyCeremony(c); err != nil {
+		return ristretto.Point{}, err
+	}
+	return c.Current(), nil
+}
+
+// Generate public parameters using the h output by a ceremony and a fresh PRF.
+func GenFromCeremony(c *Ceremony) (*PubVerPar, error) {
+	h, err := c.Finalize()
+	if err != nil {
+		return nil, err
+	}
+	return newPubVerPar(h, newPRFKey()), nil
+}
+
+// Reports whether the public parameters use the h output by a valid ceremony transcript.
+func VerifyCeremonyParams(pp *PubVerPar, c *Ceremony) bool {
+	h, err := c.Finalize()
+	return err == nil && pp.h.Equals(&h)
+}
@@ -0,0 +1,195 @@
+// Package client queries a ZKS prover served by package server.
+//
+// The client pins a commitment and the verifier params and runs Vfy on every answer before
+// reporting membership, so a dishonest or compromised server can't make it accept a wrong answer.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/smarky7cd/ZKS/wire"
+)
+
+// ErrVerification is returned when an answer does not verify against the pinned commitment.
+var ErrVerification = errors.New("client: answer does not verify against the pinned commitment")
+
+// MaxResponse is the largest response body the client reads.
+const MaxResponse = 64 << 20
+
+// A Client queries a prover and verifies its answers. It is safe for concurrent use, Pin included.
+// Binary selects the binary encoding of requests and responses instead of JSON.
+type Client struct {
+	base   string
+	hc     *http.Client
+	pp     *zks.PubVerPar
+	mu     sync.RWMutex
+	com    zks.Com
+	Binary bool
+}
+
+// Creates a client for the prover at baseURL, verifying answers with the verifier params pp
+// against the pinned commitment com. A nil hc uses http.DefaultClient.
+func New(baseURL string, pp *zks.PubVerPar, com zks.Com, hc *http.Client) *Client {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return &Client{base: strings.TrimSuffix(baseURL, "/"), hc: hc, pp: pp, com: com}
+}
+
+// Pins a new commitment, e.g. after the prover published a new set.
+// Queries in flight verify against the commitment pinned when they were sent.
+func (c *Client) Pin(com zks.Com) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.com = com
+}
+
+// The commitment currently pinned.
+func (c *Client) pinned() zks.Com {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.com
+}
+
+// Fetches the verifier params published by the prover at baseURL.
+// The params are not authenticated: pin known params, or check them with zks.VerifyParams.
+func FetchParams(ctx context.Context, baseURL string, hc *http.Client) (*zks.PubVerPar, error) {
+	var pp zks.PubVerPar
+	c := New(baseURL, nil, zks.Com{}, hc)
+	if err := c.get(ctx, "/v1/params", &pp); err != nil {
+		return nil, err
+	}
+	return &pp, nil
+}
+
+// Fetches the commitment currently published by the prover at baseURL.
+// The commitment is not authenticated: compare it with one obtained over a trusted channel.
+func FetchCommitment(ctx context.Context, baseURL string, hc *http.Client) (zks.Com, error) {
+	var com zks.Com
+	c := New(baseURL, nil, zks.Com{}, hc)
+	err := c.get(ctx, "/v1/commitment", &com)
+	return com, err
+}
+
+// Sends a request and reads the body of a successful response.
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	if c.Binary {
+		req.Header.Set("Accept", wire.BinaryType)
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponse))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("client: %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
+
+// Decodes a response body into v in the encoding the client asked for.
+func (c *Client) decode(body []byte, v any) error {
+	if m, ok := v.(encoding.BinaryUnmarshaler); ok && c.Binary {
+		return m.UnmarshalBinary(body)
+	}
+	return json.Unmarshal(body, v)
+}
+
+// Fetches path and decodes the response into v.
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+path, nil)
+	if err != nil {
+		return err
+	}
+	body, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	return c.decode(body, v)
+}
+
+// Asks the prover whether x is in the set.
+// Returns the verified answer, or ErrVerification if the answer does not verify.
+func (c *Client) Query(ctx context.Context, x uint64) (bool, error) {
+	com := c.pinned()
+	var a zks.Answer
+	if err := c.get(ctx, "/v1/query/"+strconv.FormatUint(x, 10), &a); err != nil {
+		return false, err
+	}
+	if !zks.Vfy(c.pp, com, x, &a) {
+		return false, ErrVerification
+	}
+	return a.Member(), nil
+}
+
+// Asks the prover whether each of xs is in the set.
+// Returns the verified answers in order, or ErrVerification if any answer does not verify.
+func (c *Client) QueryBatch(ctx context.Context, xs []uint64) ([]bool, error) {
+	com := c.pinned()
+	var body []byte
+	contentType := "application/json"
+	if c.Binary {
+		contentType = wire.BinaryType
+		body = wire.AppendBatchRequest(nil, xs)
+	} else {
+		body, _ = json.Marshal(&wire.BatchRequest{Xs: xs})
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"/v1/query", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := c.decodeBatch(resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(answers) != len(xs) {
+		return nil, fmt.Errorf("client: got %d answers for %d elements", len(answers), len(xs))
+	}
+
+	members := make([]bool, len(xs))
+	for i, x := range xs {
+		if !zks.Vfy(c.pp, com, x, answers[i]) {
+			return nil, ErrVerification
+		}
+		members[i] = answers[i].Member()
+	}
+	return members, nil
+}
+
+// Decodes the answers of a batch response.
+func (c *Client) decodeBatch(body []byte) ([]*zks.Answer, error) {
+	if !c.Binary {
+		var resp wire.BatchResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+		return resp.Answers, nil
+	}
+	return wire.ParseBatchResponse(body)
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/smarky7cd/ZKS/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProver(values map[uint64]bool, max uint64) (*zks.PubVerPar, *zks.Repr, zks.Com) {
+	pp := zks.Gen()
+	repr, com := zks.Rep(pp, zks.NewEnumSet(values, max))
+	return pp, repr, com
+}
+
+func TestEndToEnd(t *testing.T) {
+	values := map[uint64]bool{2: true, 3: true, 17: true, 30: true}
+	pp, repr, com := newProver(values, 32)
+	srv, err := server.New(pp, repr, com)
+	assert.Nil(t, err)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	ctx := context.Background()
+
+	params, err := FetchParams(ctx, ts.URL, nil)
+	assert.Nil(t, err)
+	pinned, err := FetchCommitment(ctx, ts.URL, nil)
+	assert.Nil(t, err)
+	assert.True(t, pinned.Equals(com))
+
+	for _, binary := range []bool{false, true} {
+		c := New(ts.URL, params, pinned, ts.Client())
+		c.Binary = binary
+
+		for x := uint64(0); x < 32; x++ {
+			member, err := c.Query(ctx, x)
+			assert.Nil(t, err)
+			assert.Equal(t, values[x], member, "answer for %d", x)
+		}
+
+		xs := []uint64{0, 2, 3, 4, 17, 31, 30}
+		members, err := c.QueryBatch(ctx, xs)
+		assert.Nil(t, err)
+		for i, x := range xs {
+			assert.Equal(t, values[x], members[i], "answer for %d", x)
+		}
+
+		_, err = c.Query(ctx, 32)
+		assert.NotNil(t, err, "no proof beyond the leaves")
+	}
+}
+
+func TestPinnedCommitment(t *testing.T) {
+	pp, repr, com := newProver(map[uint64]bool{5: true}, 16)
+	srv, err := server.New(pp, repr, com)
+	assert.Nil(t, err)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	ctx := context.Background()
+
+	c := New(ts.URL, pp, com, ts.Client())
+	member, err := c.Query(ctx, 5)
+	assert.Nil(t, err)
+	assert.True(t, member)
+
+	// once the server publishes another set, answers no longer verify against the pinned commitment
+	repr2, com2 := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{6: true}, 16))
+	assert.Nil(t, srv.Update(repr2, com2))
+	_, err = c.Query(ctx, 5)
+	assert.ErrorIs(t, err, ErrVerification)
+	_, err = c.QueryBatch(ctx, []uint64{5, 6})
+	assert.ErrorIs(t, err, ErrVerification)
+
+	// pinning while queries are in flight is safe: each verifies against one pinned commitment
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 8; i++ {
+				if _, err := c.QueryBatch(ctx, []uint64{5, 6}); err != nil {
+					assert.ErrorIs(t, err, ErrVerification)
+				}
+			}
+		}()
+	}
+	c.Pin(com2)
+	wg.Wait()
+	member, err = c.Query(ctx, 6)
+	assert.Nil(t, err)
+	assert.True(t, member)
+
+	// answers under other parameters don't verify either
+	c = New(ts.URL, zks.Gen(), com2, ts.Client())
+	_, err = c.Query(ctx, 6)
+	assert.ErrorIs(t, err, ErrVerification)
+}
@@ -0,0 +1,205 @@
+// Command zks-audit audits a prover, either by opening the whole committed set or by random challenges.
+//
+// With the prover's cooperation, the set is opened into an audit bundle that the auditor checks against
+// the published commitment:
+//
+//	zks-audit open -key prover.json -repr repr.json -bundle bundle.json
+//	zks-audit check -params params.json -com com.json -bundle bundle.json
+//
+// Without it, random elements are queried from a prover served over HTTP (see package server) and every
+// answer is verified against the published commitment:
+//
+//	zks-audit challenge -server http://localhost:8080 -params params.json -com com.json -max 1000 -n 100
+//
+// -max is required: challenges are drawn below it.
+//
+// check and challenge exit with status 1 if the audit fails.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/smarky7cd/ZKS/client"
+)
+
+// errAuditFailed is returned when a challenge answer does not verify.
+var errAuditFailed = errors.New("audit failed")
+
+// errNoChallenges is returned when a challenge run would query no element.
+var errNoChallenges = errors.New("challenge: -max and -n must be positive")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "zks-audit:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) < 1 {
+		return errors.New("usage: zks-audit <open|check|challenge> [flags]")
+	}
+	switch args[0] {
+	case "open":
+		return openCmd(args[1:], stdout)
+	case "check":
+		return checkCmd(args[1:], stdout)
+	case "challenge":
+		return challengeCmd(args[1:], stdout)
+	}
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// Reads a JSON file into v.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func openCmd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("open", flag.ContinueOnError)
+	key := fs.String("key", "prover.json", "prover key file")
+	repr := fs.String("repr", "repr.json", "representation snapshot file")
+	bundle := fs.String("bundle", "bundle.json", "audit bundle file to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var pp zks.PubVerPar
+	if err := readJSON(*key, &pp); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(*repr)
+	if err != nil {
+		return err
+	}
+	r, _, err := zks.LoadRepr(&pp, data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *repr, err)
+	}
+
+	b := r.OpenAll()
+	data, err = json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*bundle, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "wrote audit bundle %s: %d members, %d nodes\n", *bundle, len(b.Members), len(b.Nodes))
+	return nil
+}
+
+func checkCmd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	params := fs.String("params", "params.json", "verifier params file")
+	com := fs.String("com", "com.json", "published commitment file")
+	bundle := fs.String("bundle", "bundle.json", "audit bundle file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var pp zks.PubVerPar
+	if err := readJSON(*params, &pp); err != nil {
+		return err
+	}
+	var c zks.Com
+	if err := readJSON(*com, &c); err != nil {
+		return err
+	}
+	var b zks.AuditBundle
+	if err := readJSON(*bundle, &b); err != nil {
+		return err
+	}
+
+	if err := zks.VerifyAudit(&pp, c, &b); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "audit bundle opens the commitment: %d members below %d\n", len(b.Members), b.Max)
+	return nil
+}
+
+// Picks n distinct elements below max at random, every element if n >= max.
+func challenges(max uint64, n uint64) []uint64 {
+	if n >= max {
+		xs := make([]uint64, max)
+		for x := range xs {
+			xs[x] = uint64(x)
+		}
+		return xs
+	}
+
+	picked := make(map[uint64]bool)
+	var xs []uint64
+	for uint64(len(xs)) < n {
+		x := rand.Uint64N(max)
+		if !picked[x] {
+			picked[x] = true
+			xs = append(xs, x)
+		}
+	}
+	return xs
+}
+
+func challengeCmd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("challenge", flag.ContinueOnError)
+	server := fs.String("server", "http://localhost:8080", "base URL of the prover")
+	params := fs.String("params", "params.json", "verifier params file")
+	com := fs.String("com", "com.json", "published commitment file")
+	max := fs.Uint64("max", 0, "universe bound: challenges are below max (required)")
+	n := fs.Uint64("n", 100, "number of distinct elements to challenge")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *max == 0 || *n == 0 {
+		return errNoChallenges
+	}
+
+	var pp zks.PubVerPar
+	if err := readJSON(*params, &pp); err != nil {
+		return err
+	}
+	var c zks.Com
+	if err := readJSON(*com, &c); err != nil {
+		return err
+	}
+
+	cl := client.New(*server, &pp, c, nil)
+	ctx := context.Background()
+	var members, failures int
+	xs := challenges(*max, *n)
+	for _, x := range xs {
+		member, err := cl.Query(ctx, x)
+		switch {
+		case errors.Is(err, client.ErrVerification):
+			failures++
+			fmt.Fprintf(stdout, "%d: answer does not verify\n", x)
+		case err != nil:
+			return fmt.Errorf("query %d: %w", x, err)
+		case member:
+			members++
+		}
+	}
+
+	coverage := 100 * float64(len(xs)) / float64(*max)
+	fmt.Fprintf(stdout, "challenged %d of %d elements (%.2f%% coverage): %d members, %d non-members, %d failures\n",
+		len(xs), *max, coverage, members, len(xs)-members-failures, failures)
+	if failures > 0 {
+		return errAuditFailed
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/smarky7cd/ZKS/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	write := func(name string, v any) {
+		data, _ := json.Marshal(v)
+		os.WriteFile(path(name), data, 0o600)
+	}
+	var out bytes.Buffer
+
+	pp := zks.Gen()
+	repr, com := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{3: true, 10: true, 42: true}, 64))
+	key, _ := pp.MarshalProverKey()
+	os.WriteFile(path("prover.json"), key, 0o600)
+	write("params.json", pp)
+	write("repr.json", repr)
+	write("com.json", com)
+	_, other := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{3: true}, 64))
+	write("other.json", other)
+
+	assert.Nil(t, run([]string{"open", "-key", path("prover.json"), "-repr", path("repr.json"), "-bundle", path("bundle.json")}, &out))
+	assert.Contains(t, out.String(), "3 members")
+
+	out.Reset()
+	assert.Nil(t, run([]string{"check", "-params", path("params.json"), "-com", path("com.json"), "-bundle", path("bundle.json")}, &out))
+	assert.Contains(t, out.String(), "opens the commitment")
+	assert.NotNil(t, run([]string{"check", "-params", path("params.json"), "-com", path("other.json"), "-bundle", path("bundle.json")}, &out))
+
+	srv, err := server.New(pp, repr, com)
+	assert.Nil(t, err)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	out.Reset()
+	assert.Nil(t, run([]string{"challenge", "-server", ts.URL, "-params", path("params.json"), "-com", path("com.json"),
+		"-max", "64", "-n", "16"}, &out))
+	assert.Contains(t, out.String(), "challenged 16 of 64 elements (25.00% coverage)")
+
+	out.Reset()
+	assert.Nil(t, run([]string{"challenge", "-server", ts.URL, "-params", path("params.json"), "-com", path("com.json"),
+		"-max", "64", "-n", "100"}, &out))
+	assert.Contains(t, out.String(), "(100.00% coverage): 3 members, 61 non-members, 0 failures")
+
+	// a challenge without a universe bound or elements to query is refused, not reported as covering it
+	out.Reset()
+	err = run([]string{"challenge", "-server", ts.URL, "-params", path("params.json"), "-com", path("com.json")}, &out)
+	assert.ErrorIs(t, err, errNoChallenges)
+	err = run([]string{"challenge", "-server", ts.URL, "-params", path("params.json"), "-com", path("com.json"),
+		"-max", "64", "-n", "0"}, &out)
+	assert.ErrorIs(t, err, errNoChallenges)
+	assert.Empty(t, out.String())
+
+	// answers that don't verify against the published commitment fail the audit
+	out.Reset()
+	err = run([]string{"challenge", "-server", ts.URL, "-params", path("params.json"), "-com", path("other.json"),
+		"-max", "64", "-n", "8"}, &out)
+	assert.ErrorIs(t, err, errAuditFailed)
+	assert.Contains(t, out.String(), "8 failures")
+}
@@ -0,0 +1,143 @@
+// Command zks-ceremony runs a multi-party ceremony generating the ZKS commitment base h.
+//
+// The transcript is a JSON file passed from participant to participant:
+//
+//	zks-ceremony init -domain "acme zks" -out transcript.json
+//	zks-ceremony contribute -name alice -in transcript.json -out transcript.json
+//	zks-ceremony verify -in transcript.json
+//	zks-ceremony finalize -in transcript.json -out h.json
+//
+// Every command verifies the transcript it reads, so a participant never builds on an invalid one.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	zks "github.com/smarky7cd/ZKS"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "zks-ceremony:", err)
+		os.Exit(1)
+	}
+}
+
+// Runs the subcommand named by the first argument, writing its report to stdout.
+func run(args []string, stdout io.Writer) error {
+	if len(args) < 1 {
+		return errors.New("usage: zks-ceremony <init|contribute|verify|finalize> [flags]")
+	}
+
+	switch args[0] {
+	case "init":
+		return initCmd(args[1:])
+	case "contribute":
+		return contributeCmd(args[1:], stdout)
+	case "verify":
+		return verifyCmd(args[1:], stdout)
+	case "finalize":
+		return finalizeCmd(args[1:], stdout)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// Reads and verifies a transcript.
+func readTranscript(path string) (*zks.Ceremony, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c zks.Ceremony
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := zks.VerifyCeremony(&c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Writes v as indented JSON.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func initCmd(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	domain := fs.String("domain", zks.DefaultParamsDomain, "domain string binding the ceremony")
+	out := fs.String("out", "transcript.json", "transcript file to create")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return writeJSON(*out, zks.NewCeremony(*domain))
+}
+
+func contributeCmd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("contribute", flag.ContinueOnError)
+	name := fs.String("name", "", "name of the contributing participant")
+	in := fs.String("in", "transcript.json", "transcript file to read")
+	out := fs.String("out", "transcript.json", "transcript file to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("contribute: -name is required")
+	}
+	c, err := readTranscript(*in)
+	if err != nil {
+		return err
+	}
+	contrib := c.Contribute(*name)
+	fmt.Fprintf(stdout, "contribution %d by %s: h = %v\n", len(c.Contributions), *name, contrib.H)
+	return writeJSON(*out, c)
+}
+
+func verifyCmd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	in := fs.String("in", "transcript.json", "transcript file to verify")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := readTranscript(*in)
+	if err != nil {
+		return err
+	}
+	for i, contrib := range c.Contributions {
+		fmt.Fprintf(stdout, "%d %s %v ok\n", i+1, contrib.Participant, contrib.H)
+	}
+	return nil
+}
+
+func finalizeCmd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("finalize", flag.ContinueOnError)
+	in := fs.String("in", "transcript.json", "transcript file to finalize")
+	out := fs.String("out", "h.json", "file receiving the final h")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := readTranscript(*in)
+	if err != nil {
+		return err
+	}
+	h, err := c.Finalize()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "h = %v (%d contributions)\n", h, len(c.Contributions))
+	return writeJSON(*out, map[string]any{"domain": c.Domain, "h": &h})
+}
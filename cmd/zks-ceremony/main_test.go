@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwesterb/go-ristretto"
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCeremony(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	var out bytes.Buffer
+
+	assert.Nil(t, run([]string{"init", "-domain", "test zks", "-out", path("transcript.json")}, &out))
+
+	// a transcript without contributions can't be finalized
+	err := run([]string{"finalize", "-in", path("transcript.json"), "-out", path("h.json")}, &out)
+	assert.ErrorIs(t, err, zks.ErrEmptyCeremony)
+
+	for _, name := range []string{"alice", "bob"} {
+		out.Reset()
+		assert.Nil(t, run([]string{"contribute", "-name", name, "-in", path("transcript.json"), "-out", path("transcript.json")}, &out))
+		assert.Contains(t, out.String(), "by "+name)
+	}
+	assert.NotNil(t, run([]string{"contribute", "-in", path("transcript.json")}, &out), "-name is required")
+
+	out.Reset()
+	assert.Nil(t, run([]string{"verify", "-in", path("transcript.json")}, &out))
+	assert.Contains(t, out.String(), "1 alice")
+	assert.Contains(t, out.String(), "2 bob")
+
+	out.Reset()
+	assert.Nil(t, run([]string{"finalize", "-in", path("transcript.json"), "-out", path("h.json")}, &out))
+	assert.Contains(t, out.String(), "(2 contributions)")
+
+	// the final h is the one the transcript yields
+	var c zks.Ceremony
+	data, _ := os.ReadFile(path("transcript.json"))
+	assert.Nil(t, json.Unmarshal(data, &c))
+	pp, err := zks.GenFromCeremony(&c)
+	assert.Nil(t, err)
+	var final struct {
+		Domain string          `json:"domain"`
+		H      ristretto.Point `json:"h"`
+	}
+	data, _ = os.ReadFile(path("h.json"))
+	assert.Nil(t, json.Unmarshal(data, &final))
+	assert.Equal(t, "test zks", final.Domain)
+	assert.True(t, zks.VerifyCeremonyParams(pp, &c))
+	h, _ := c.Finalize()
+	assert.True(t, final.H.Equals(&h))
+
+	// a tampered transcript is rejected by every command
+	transcript, _ := os.ReadFile(path("transcript.json"))
+	os.WriteFile(path("tampered.json"), []byte(strings.Replace(string(transcript), `"alice"`, `"mallory"`, 1)), 0o644)
+	for _, cmd := range [][]string{
+		{"verify", "-in", path("tampered.json")},
+		{"contribute", "-name", "carol", "-in", path("tampered.json"), "-out", path("out.json")},
+		{"finalize", "-in", path("tampered.json"), "-out", path("out.json")},
+	} {
+		assert.NotNil(t, run(cmd, &out), "%s accepts a tampered transcript", cmd[0])
+	}
+	assert.NotNil(t, run([]string{"unknown"}, &out))
+}
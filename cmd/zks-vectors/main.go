@@ -0,0 +1,85 @@
+// Command zks-vectors writes the known-answer test vectors of the ZKS as JSON.
+//
+// Every vector is generated from a seed with zks.GenFromSeed, so the output only changes when the
+// construction does. The test suite regenerates testdata/vectors.json and compares it byte-for-byte:
+//
+//	zks-vectors -o testdata/vectors.json
+//	zks-vectors -seed "my seed" -o vectors.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+
+	zks "github.com/smarky7cd/ZKS"
+)
+
+// A vector of the suite: a set over a universe, the shape of its tree and the elements queried.
+type vectorCase struct {
+	max     uint64
+	members []uint64
+	opts    zks.TreeOptions
+	xs      []uint64
+}
+
+// The elements 0 to max-1.
+func universe(max uint64) []uint64 {
+	xs := make([]uint64, max)
+	for x := range xs {
+		xs[x] = uint64(x)
+	}
+	return xs
+}
+
+// The suite covers the degenerate universes, every arity and a fixed-depth tree over all of uint64.
+var suite = []vectorCase{
+	{0, nil, zks.TreeOptions{}, []uint64{0}},
+	{1, []uint64{0}, zks.TreeOptions{}, []uint64{0}},
+	{16, []uint64{0, 3, 9, 14}, zks.TreeOptions{}, universe(16)},
+	{16, []uint64{0, 3, 9, 14}, zks.TreeOptions{Arity: 4}, universe(16)},
+	{64, []uint64{1, 2, 33, 63}, zks.TreeOptions{Arity: 16}, []uint64{0, 1, 2, 32, 33, 63}},
+	{math.MaxUint64, []uint64{7, 1 << 40, math.MaxUint64 - 1}, zks.TreeOptions{Depth: 64}, []uint64{0, 7, 1 << 40, math.MaxUint64 - 1, math.MaxUint64}},
+}
+
+// The seed testdata/vectors.json is generated from.
+const defaultSeed = "ZKS test vectors v1"
+
+func main() {
+	seed := flag.String("seed", defaultSeed, "seed the parameters are generated from")
+	out := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	if err := run(*seed, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "zks-vectors:", err)
+		os.Exit(1)
+	}
+}
+
+func run(seed string, out string) error {
+	vectors := []*zks.TestVector{}
+	for _, c := range suite {
+		es := zks.NewEnumSet(make(map[uint64]bool), c.max)
+		for _, x := range c.members {
+			es.Add(x)
+		}
+		v, err := zks.GenTestVector([]byte(seed), es, c.opts, c.xs)
+		if err != nil {
+			return err
+		}
+		vectors = append(vectors, v)
+	}
+
+	data, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(out, data, 0o644)
+}
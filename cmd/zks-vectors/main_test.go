@@ -0,0 +1,21 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVectorsUpToDate(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "vectors.json")
+	assert.Nil(t, run(defaultSeed, out))
+
+	got, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	want, err := os.ReadFile("../../testdata/vectors.json")
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(want, got), "testdata/vectors.json is stale, rebuild it with go generate")
+}
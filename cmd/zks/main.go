@@ -0,0 +1,254 @@
+// Command zks generates parameters for, commits to, queries and verifies zero-knowledge sets.
+//
+// Every file it reads or writes is JSON:
+//
+//	zks gen -key prover.json -params params.json
+//	zks commit -key prover.json -members members.txt -max 1000 -repr repr.json -com com.json [-log coms.log]
+//	zks query -key prover.json -repr repr.json -x 42 -proof proof.json
+//	zks verify -params params.json -com com.json -proof proof.json
+//
+// The prover key holds the PRF key in cleartext and must be kept secret. The verifier params,
+// commitment and proofs can be published. The member list has one integer per line (blank lines
+// and lines starting with # are ignored), or is a CSV file (-column) or a JSON array. With -hashed,
+// members are string keys hashed into the universe.
+//
+// verify exits with status 0 if the proof is valid, whatever the answer, and 1 otherwise.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	zks "github.com/smarky7cd/ZKS"
+)
+
+// errInvalidProof is returned by verify when the proof does not verify.
+var errInvalidProof = errors.New("invalid proof")
+
+// A proof file: the queried element and the answer to the query.
+type proofFile struct {
+	X      uint64      `json:"x"`
+	Answer *zks.Answer `json:"answer"`
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "zks:", err)
+		os.Exit(1)
+	}
+}
+
+// Runs the subcommand named by the first argument, writing its report to stdout.
+func run(args []string, stdout io.Writer) error {
+	if len(args) < 1 {
+		return errors.New("usage: zks <gen|commit|query|verify> [flags]")
+	}
+
+	switch args[0] {
+	case "gen":
+		return genCmd(args[1:], stdout)
+	case "commit":
+		return commitCmd(args[1:], stdout)
+	case "query":
+		return queryCmd(args[1:], stdout)
+	case "verify":
+		return verifyCmd(args[1:], stdout)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// Reads a JSON file into v.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Writes v to a JSON file.
+func writeJSON(path string, v any, perm os.FileMode) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), perm)
+}
+
+func genCmd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	key := fs.String("key", "prover.json", "prover key file to write")
+	params := fs.String("params", "params.json", "verifier params file to write")
+	seed := fs.String("seed", "", "derive h from this public seed instead of at random")
+	domain := fs.String("domain", zks.DefaultParamsDomain, "domain string used with -seed")
+	ceremony := fs.String("ceremony", "", "take h from this ceremony transcript instead of at random")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var pp *zks.PubVerPar
+	switch {
+	case *seed != "" && *ceremony != "":
+		return errors.New("gen: -seed and -ceremony are exclusive")
+	case *seed != "":
+		pp = zks.GenVerifiable([]byte(*seed), *domain)
+	case *ceremony != "":
+		var c zks.Ceremony
+		if err := readJSON(*ceremony, &c); err != nil {
+			return err
+		}
+		var err error
+		if pp, err = zks.GenFromCeremony(&c); err != nil {
+			return err
+		}
+	default:
+		pp = zks.Gen()
+	}
+
+	data, err := pp.MarshalProverKey()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*key, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	if err := writeJSON(*params, pp, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "wrote prover key %s and verifier params %s\n", *key, *params)
+	return nil
+}
+
+func commitCmd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("commit", flag.ContinueOnError)
+	key := fs.String("key", "prover.json", "prover key file")
+	members := fs.String("members", "members.txt", "member list: one per line, or a .csv or .json file")
+	max := fs.Uint64("max", 0, "universe bound: members are below max")
+	arity := fs.Uint64("arity", 2, "arity of the tree")
+	depth := fs.Uint64("depth", 0, "fixed depth of the tree, 0 to derive it from -max")
+	column := fs.String("column", "", "CSV column holding the members (default the first)")
+	hashed := fs.Bool("hashed", false, "members are string keys hashed into the universe")
+	repr := fs.String("repr", "repr.json", "representation snapshot file to write")
+	com := fs.String("com", "com.json", "commitment file to write")
+	log := fs.String("log", "", "commitment log to append the commitment to (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var pp zks.PubVerPar
+	if err := readJSON(*key, &pp); err != nil {
+		return err
+	}
+	es, err := zks.LoadFile(*members, zks.LoadOptions{Max: *max, Column: *column, Hashed: *hashed})
+	if err != nil {
+		return err
+	}
+
+	// committing under the log publishes the commitment to it
+	prover := &pp
+	var l *zks.CommitLog
+	if *log != "" {
+		if l, err = zks.OpenLog(*log); err != nil {
+			return err
+		}
+		defer l.Close()
+		prover = pp.WithLog(l)
+	}
+	r, c, err := zks.RepWithOptions(prover, es, zks.TreeOptions{Arity: *arity, Depth: *depth})
+	if err != nil {
+		return err
+	}
+
+	if err := writeJSON(*repr, r, 0o600); err != nil {
+		return err
+	}
+	if err := writeJSON(*com, c, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "wrote representation %s and commitment %s\n", *repr, *com)
+	if l != nil {
+		fmt.Fprintf(stdout, "commitment is entry %d of %s\n", l.Size()-1, *log)
+	}
+	return nil
+}
+
+func queryCmd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	key := fs.String("key", "prover.json", "prover key file")
+	repr := fs.String("repr", "repr.json", "representation snapshot file")
+	com := fs.String("com", "", "commitment file the snapshot must match (optional)")
+	x := fs.Uint64("x", 0, "element to query")
+	proof := fs.String("proof", "proof.json", "proof file to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var pp zks.PubVerPar
+	if err := readJSON(*key, &pp); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(*repr)
+	if err != nil {
+		return err
+	}
+	r, c, err := zks.LoadRepr(&pp, data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *repr, err)
+	}
+	if *com != "" {
+		var want zks.Com
+		if err := readJSON(*com, &want); err != nil {
+			return err
+		}
+		if !c.Equals(want) {
+			return fmt.Errorf("%s does not match commitment %s", *repr, *com)
+		}
+	}
+
+	a := zks.Qry(&pp, r, *x)
+	if a == nil {
+		return fmt.Errorf("query: %d is beyond the leaves of the tree", *x)
+	}
+	if err := writeJSON(*proof, &proofFile{*x, a}, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%d member=%t\n", *x, a.Member())
+	return nil
+}
+
+func verifyCmd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	params := fs.String("params", "params.json", "verifier params file")
+	com := fs.String("com", "com.json", "commitment file")
+	proof := fs.String("proof", "proof.json", "proof file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var pp zks.PubVerPar
+	if err := readJSON(*params, &pp); err != nil {
+		return err
+	}
+	var c zks.Com
+	if err := readJSON(*com, &c); err != nil {
+		return err
+	}
+	var p proofFile
+	if err := readJSON(*proof, &p); err != nil {
+		return err
+	}
+
+	if !zks.Vfy(&pp, c, p.X, p.Answer) {
+		return errInvalidProof
+	}
+	fmt.Fprintf(stdout, "%d member=%t verified\n", p.X, p.Answer.Member())
+	return nil
+}
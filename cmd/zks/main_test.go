@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	var out bytes.Buffer
+
+	os.WriteFile(path("members.txt"), []byte("# members\n3\n\n10\n42\n"), 0o644)
+
+	assert.Nil(t, run([]string{"gen", "-key", path("prover.json"), "-params", path("params.json")}, &out))
+	assert.Nil(t, run([]string{"commit", "-key", path("prover.json"), "-members", path("members.txt"), "-max", "64",
+		"-repr", path("repr.json"), "-com", path("com.json")}, &out))
+
+	for _, q := range []struct {
+		x      string
+		member string
+	}{{"3", "true"}, {"42", "true"}, {"4", "false"}, {"63", "false"}} {
+		out.Reset()
+		assert.Nil(t, run([]string{"query", "-key", path("prover.json"), "-repr", path("repr.json"), "-com", path("com.json"),
+			"-x", q.x, "-proof", path("proof.json")}, &out))
+
+		out.Reset()
+		assert.Nil(t, run([]string{"verify", "-params", path("params.json"), "-com", path("com.json"), "-proof", path("proof.json")}, &out))
+		assert.Contains(t, out.String(), q.x+" member="+q.member)
+	}
+
+	// commitments are appended to the log
+	for i, members := range []string{"3\n", "3\n4\n"} {
+		os.WriteFile(path("log-members.txt"), []byte(members), 0o644)
+		out.Reset()
+		assert.Nil(t, run([]string{"commit", "-key", path("prover.json"), "-members", path("log-members.txt"), "-max", "64",
+			"-repr", path("repr2.json"), "-com", path("com2.json"), "-log", path("coms.log")}, &out))
+		assert.Contains(t, out.String(), fmt.Sprintf("entry %d of", i))
+	}
+
+	// the verifier params never contain the PRF key
+	params, _ := os.ReadFile(path("params.json"))
+	assert.NotContains(t, string(params), "prf")
+
+	// a proof for one element doesn't verify for another
+	proof, _ := os.ReadFile(path("proof.json"))
+	os.WriteFile(path("proof.json"), []byte(strings.Replace(string(proof), `"x": 63`, `"x": 62`, 1)), 0o644)
+	err := run([]string{"verify", "-params", path("params.json"), "-com", path("com.json"), "-proof", path("proof.json")}, &out)
+	assert.ErrorIs(t, err, errInvalidProof)
+
+	// the verifier params can't stand in for the prover key
+	err = run([]string{"commit", "-key", path("params.json"), "-members", path("members.txt"), "-max", "64",
+		"-repr", path("repr4.json"), "-com", path("com4.json")}, &out)
+	assert.ErrorIs(t, err, zks.ErrNoProverKey)
+	err = run([]string{"query", "-key", path("params.json"), "-repr", path("repr.json"), "-x", "3", "-proof", path("proof4.json")}, &out)
+	assert.ErrorIs(t, err, zks.ErrNoProverKey)
+
+	// members must lie in the universe, errors name the line
+	os.WriteFile(path("bad.txt"), []byte("1\n2\n99\n"), 0o644)
+	err = run([]string{"commit", "-key", path("prover.json"), "-members", path("bad.txt"), "-max", "64",
+		"-repr", path("repr.json"), "-com", path("com.json")}, &out)
+	assert.ErrorContains(t, err, "bad.txt:3")
+
+	// CSV member lists pick a column
+	os.WriteFile(path("members.csv"), []byte("name,id\nalice,3\nbob,42\n"), 0o644)
+	out.Reset()
+	assert.Nil(t, run([]string{"commit", "-key", path("prover.json"), "-members", path("members.csv"), "-column", "id", "-max", "64",
+		"-repr", path("repr3.json"), "-com", path("com3.json")}, &out))
+	assert.Nil(t, run([]string{"query", "-key", path("prover.json"), "-repr", path("repr3.json"), "-x", "42", "-proof", path("proof3.json")}, &out))
+	out.Reset()
+	assert.Nil(t, run([]string{"verify", "-params", path("params.json"), "-com", path("com3.json"), "-proof", path("proof3.json")}, &out))
+	assert.Contains(t, out.String(), "42 member=true")
+}
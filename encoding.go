@@ -0,0 +1,403 @@
+package zks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/bwesterb/go-ristretto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	"github.com/smarky7cd/ZKS/verify"
+)
+
+// JSON and binary encodings of the ZKS values, used for the files of the zks command and for transport.
+// In JSON points and scalars are encoded as unpadded base64url strings, hashes as base64 strings.
+
+// ErrMalformed is returned when decoding a value that doesn't have the expected shape.
+var ErrMalformed = errors.New("zks: malformed encoding")
+
+// ErrNoProverKey is returned when prover operations are given verifier parameters.
+var ErrNoProverKey = errors.New("zks: parameters hold no PRF key")
+
+type paramsJSON struct {
+	H   ristretto.Point `json:"h"`
+	PRF []byte          `json:"prf,omitempty"`
+}
+
+// Encodes the verifier parameters, that is h only: the PRF key never leaves the prover this way.
+func (pp *PubVerPar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&paramsJSON{H: pp.h})
+}
+
+// Encodes the prover key, that is h and the PRF key.
+// The PRF key is stored in cleartext and must be kept secret.
+func (pp *PubVerPar) MarshalProverKey() ([]byte, error) {
+	if pp.kh == nil {
+		return nil, ErrNoProverKey
+	}
+	var buf bytes.Buffer
+	if err := insecurecleartextkeyset.Write(pp.kh, keyset.NewBinaryWriter(&buf)); err != nil {
+		return nil, err
+	}
+	return json.Marshal(&paramsJSON{pp.h, buf.Bytes()})
+}
+
+// Decodes verifier parameters, or a prover key if the PRF key is present.
+func (pp *PubVerPar) UnmarshalJSON(data []byte) error {
+	var v paramsJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.PRF == nil {
+		*pp = PubVerPar{h: v.H}
+		return nil
+	}
+
+	kh, err := insecurecleartextkeyset.Read(keyset.NewBinaryReader(bytes.NewReader(v.PRF)))
+	if err != nil {
+		return err
+	}
+	*pp = *newPubVerPar(v.H, kh)
+	return nil
+}
+
+// A master key holds h and its secret in cleartext.
+type masterKeyJSON struct {
+	H      ristretto.Point `json:"h"`
+	Secret []byte          `json:"secret"`
+}
+
+// Encodes the master key, including its secret.
+func (mk *MasterKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&masterKeyJSON{mk.h, mk.secret})
+}
+
+// Decodes a master key. Fails unless it holds a 32-byte secret.
+func (mk *MasterKey) UnmarshalJSON(data []byte) error {
+	var v masterKeyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if len(v.Secret) != masterSecretSize {
+		return ErrMalformed
+	}
+	*mk = MasterKey{h: v.H, secret: v.Secret}
+	return nil
+}
+
+type comJSON struct {
+	C0 ristretto.Point `json:"c0"`
+	C1 ristretto.Point `json:"c1"`
+}
+
+// Encodes the commitment.
+func (c Com) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&comJSON{c.c0, c.c1})
+}
+
+// Decodes a commitment.
+func (c *Com) UnmarshalJSON(data []byte) error {
+	var v comJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = Com{v.C0, v.C1}
+	return nil
+}
+
+// Reports whether two commitments are equal.
+func (c Com) Equals(d Com) bool {
+	return c.c0.Equals(&d.c0) && c.c1.Equals(&d.c1)
+}
+
+type openJSON struct {
+	R0 ristretto.Scalar `json:"r0"`
+	R1 ristretto.Scalar `json:"r1"`
+}
+
+// The commitments (xcoms, sibcoms, vopens) are listed from level 1 down to the leaves,
+// the openings or teases from the root (level 0) down to the leaves.
+type answerJSON struct {
+	Member  bool               `json:"member"`
+	Levels  uint64             `json:"levels"`
+	Arity   uint64             `json:"arity"`
+	Prefix  uint64             `json:"prefix,omitempty"`
+	XComs   []Com              `json:"xcoms"`
+	SibComs []Com              `json:"sibcoms,omitempty"`
+	VOpens  [][][]byte         `json:"vopens,omitempty"`
+	Opens   []openJSON         `json:"opens,omitempty"`
+	Teases  []ristretto.Scalar `json:"teases,omitempty"`
+}
+
+// Encodes the answer.
+func (a *Answer) MarshalJSON() ([]byte, error) {
+	v := answerJSON{Member: a.answer, Levels: a.levels, Arity: a.arity, Prefix: a.prefix, XComs: []Com{}}
+	for j := uint64(1); j <= a.levels; j++ {
+		v.XComs = append(v.XComs, *a.xcoms[j])
+		if a.arity == 2 {
+			v.SibComs = append(v.SibComs, *a.sibcoms[j])
+		} else {
+			v.VOpens = append(v.VOpens, a.vopens[j])
+		}
+	}
+	for j := uint64(0); j <= a.levels; j++ {
+		if a.answer {
+			v.Opens = append(v.Opens, openJSON{a.opens[j].r0, a.opens[j].r1})
+		} else {
+			v.Teases = append(v.Teases, *a.teases[j])
+		}
+	}
+	return json.Marshal(&v)
+}
+
+// Decodes an answer. Fails if the lists don't match the number of levels.
+func (a *Answer) UnmarshalJSON(data []byte) error {
+	var v answerJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	n := uint64(len(v.XComs))
+	if v.Levels != n || !ValidArity(v.Arity) || v.Prefix > n {
+		return ErrMalformed
+	}
+	if v.Arity == 2 && uint64(len(v.SibComs)) != n || v.Arity != 2 && uint64(len(v.VOpens)) != n {
+		return ErrMalformed
+	}
+	if v.Member && uint64(len(v.Opens)) != n+1 || !v.Member && uint64(len(v.Teases)) != n+1 {
+		return ErrMalformed
+	}
+
+	*a = Answer{
+		answer:  v.Member,
+		levels:  v.Levels,
+		arity:   v.Arity,
+		prefix:  v.Prefix,
+		xcoms:   make(map[uint64]*Com),
+		sibcoms: make(map[uint64]*Com),
+		vopens:  make(map[uint64][][]byte),
+		opens:   make(map[uint64]*Open),
+		teases:  make(map[uint64]*Tease),
+	}
+	for j := uint64(1); j <= n; j++ {
+		a.xcoms[j] = &v.XComs[j-1]
+		if v.Arity == 2 {
+			a.sibcoms[j] = &v.SibComs[j-1]
+		} else {
+			a.vopens[j] = v.VOpens[j-1]
+		}
+	}
+	for j := uint64(0); j <= n; j++ {
+		if v.Member {
+			a.opens[j] = &Open{v.Opens[j].R0, v.Opens[j].R1}
+		} else {
+			a.teases[j] = &v.Teases[j]
+		}
+	}
+	return nil
+}
+
+// A growth proof lists the commitments of the new levels (xcoms and sibcoms or vopens) from level 1 down to the
+// old root, and the openings of the new levels from the root down.
+type growthJSON struct {
+	Arity   uint64     `json:"arity"`
+	XComs   []Com      `json:"xcoms"`
+	SibComs []Com      `json:"sibcoms,omitempty"`
+	VOpens  [][][]byte `json:"vopens,omitempty"`
+	Opens   []openJSON `json:"opens"`
+}
+
+// Encodes the growth proof.
+func (proof *GrowthProof) MarshalJSON() ([]byte, error) {
+	l := &proof.link
+	v := growthJSON{Arity: l.Arity, XComs: []Com{}, Opens: []openJSON{}}
+	for _, step := range l.Path {
+		v.XComs = append(v.XComs, Com{step.Com.C0, step.Com.C1})
+		if l.Arity == 2 {
+			v.SibComs = append(v.SibComs, Com{step.Sib.C0, step.Sib.C1})
+		} else {
+			v.VOpens = append(v.VOpens, step.VOpen)
+		}
+	}
+	for _, pi := range l.Opens {
+		v.Opens = append(v.Opens, openJSON{pi.R0, pi.R1})
+	}
+	return json.Marshal(&v)
+}
+
+// Decodes a growth proof. Fails if the lists don't have the same length.
+func (proof *GrowthProof) UnmarshalJSON(data []byte) error {
+	var v growthJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	n := len(v.XComs)
+	if !ValidArity(v.Arity) || len(v.Opens) != n {
+		return ErrMalformed
+	}
+	if v.Arity == 2 && len(v.SibComs) != n || v.Arity != 2 && len(v.VOpens) != n {
+		return ErrMalformed
+	}
+
+	l := verify.Link{Arity: v.Arity}
+	for j := range v.XComs {
+		step := verify.Step{Com: v.XComs[j].verifiable()}
+		if v.Arity == 2 {
+			step.Sib = v.SibComs[j].verifiable()
+		} else {
+			step.VOpen = v.VOpens[j]
+		}
+		l.Path = append(l.Path, step)
+		l.Opens = append(l.Opens, verify.Open{R0: v.Opens[j].R0, R1: v.Opens[j].R1})
+	}
+	*proof = GrowthProof{l}
+	return nil
+}
+
+// An answer in a forest: the path to the set in the top-level tree, and the commitment and answer in the set if the forest holds it.
+type forestAnswerJSON struct {
+	Set    bool    `json:"set"`
+	Path   *Answer `json:"path"`
+	Com    *Com    `json:"commitment,omitempty"`
+	Answer *Answer `json:"answer,omitempty"`
+}
+
+// Encodes the answer.
+func (a *ForestAnswer) MarshalJSON() ([]byte, error) {
+	v := forestAnswerJSON{Set: a.set, Path: a.path}
+	if a.set {
+		v.Com, v.Answer = &a.com, a.answer
+	}
+	return json.Marshal(&v)
+}
+
+// Decodes an answer. Fails if the answer in the set is missing.
+func (a *ForestAnswer) UnmarshalJSON(data []byte) error {
+	var v forestAnswerJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Path == nil || v.Set && (v.Com == nil || v.Answer == nil) {
+		return ErrMalformed
+	}
+
+	*a = ForestAnswer{set: v.Set, path: v.Path}
+	if v.Set {
+		a.com, a.answer = *v.Com, v.Answer
+	}
+	return nil
+}
+
+// The set-membership response of the answer. Only meaningful once the answer verifies.
+func (a *Answer) Member() bool {
+	return a.answer
+}
+
+// The number of levels of the tree the answer was computed from.
+func (a *Answer) Levels() uint64 {
+	return a.levels
+}
+
+// A snapshot of a ZKS representation holds the set and the shape of the tree only.
+// The tree itself is recomputed from the PRF by LoadRepr.
+type reprJSON struct {
+	Max     uint64   `json:"max"`
+	Arity   uint64   `json:"arity"`
+	Depth   uint64   `json:"depth"`
+	Grown   []uint64 `json:"grown,omitempty"`
+	Members []uint64 `json:"members"`
+}
+
+// Encodes a snapshot of the representation: its universe, tree shape, growth history and sorted members.
+func (repr *Repr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&reprJSON{repr.max, repr.tree.arity, repr.tree.levels, repr.tree.grown, repr.tree.members()})
+}
+
+// Input: public parameters (h,ps) holding the PRF key and a snapshot written by Repr.MarshalJSON.
+// Return: ZKS representation and a commitment to it, identical to the ones the snapshot was taken of.
+func LoadRepr(pp *PubVerPar, data []byte) (*Repr, Com, error) {
+	if pp.kh == nil {
+		return nil, Com{}, ErrNoProverKey
+	}
+
+	var v reprJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, Com{}, err
+	}
+
+	set, err := NewSortedSet(v.Members, v.Max)
+	if err != nil {
+		return nil, Com{}, err
+	}
+	if len(v.Grown) == 0 {
+		return RepWithOptions(pp, set, TreeOptions{Arity: v.Arity, Depth: v.Depth})
+	}
+
+	if _, _, err := treeShape(v.Max, TreeOptions{Arity: v.Arity, Depth: v.Depth}); err != nil {
+		return nil, Com{}, err
+	}
+	tree, err := buildGrownTree(pp, set, v.Depth, v.Arity, v.Grown)
+	if err != nil {
+		return nil, Com{}, err
+	}
+	com := Com{tree.root.c0, tree.root.c1}
+	if err := pp.Publish(com); err != nil {
+		return nil, Com{}, err
+	}
+	return &Repr{*tree, v.Max}, com, nil
+}
+
+// Binary encodings are fixed-size concatenations of the 32-byte encodings of points and scalars,
+// with lengths as uvarints. They are defined by the verify package, so verifiers can decode them without the ZKS.
+
+// Encodes the verifier parameters (h) as 32 bytes.
+func (pp *PubVerPar) MarshalBinary() ([]byte, error) {
+	return pp.verifiable().MarshalBinary()
+}
+
+// Decodes verifier parameters.
+func (pp *PubVerPar) UnmarshalBinary(data []byte) error {
+	v, err := verify.ParseParams(data)
+	if err != nil {
+		return ErrMalformed
+	}
+	*pp = PubVerPar{h: v.H}
+	return nil
+}
+
+// Encodes the commitment as 64 bytes.
+func (c Com) MarshalBinary() ([]byte, error) {
+	return c.verifiable().MarshalBinary()
+}
+
+// Decodes a commitment.
+func (c *Com) UnmarshalBinary(data []byte) error {
+	v, err := verify.ParseCom(data)
+	if err != nil {
+		return ErrMalformed
+	}
+	*c = Com{v.C0, v.C1}
+	return nil
+}
+
+// Encodes the answer: a version byte, the membership byte, the levels and arity as uvarints,
+// the commitments of levels 1 to levels and the openings or teases of levels 0 to levels.
+func (a *Answer) MarshalBinary() ([]byte, error) {
+	v := a.verifiable()
+	if v == nil {
+		return nil, ErrMalformed
+	}
+	return v.MarshalBinary()
+}
+
+// Decodes an answer.
+func (a *Answer) UnmarshalBinary(data []byte) error {
+	v, err := verify.ParseAnswer(data)
+	if err != nil {
+		return ErrMalformed
+	}
+	*a = *answerFrom(v)
+	return nil
+}
@@ -1,5 +1,10 @@
 package zks
 
+import (
+	"iter"
+	"slices"
+)
+
 // An enumerated set.
 //
 // Values in the set are integers and the boolean value they map to indicates set membership.
@@ -18,14 +23,14 @@ func NewEnumSet(set map[uint64]bool, max uint64) *EnumSet {
 
 // Add a value x to the EnumSet if it is less than the maximum value.
 func (es *EnumSet) Add(x uint64) {
-	if x < es.max {
+	if inUniverse(x, es.max) {
 		es.set[x] = true
 	}
 }
 
 // Remove a value x from the EnumSet if its less than the maximum value.
 func (es *EnumSet) Remove(x uint64) {
-	if x < es.max {
+	if inUniverse(x, es.max) {
 		es.set[x] = false
 	}
 }
@@ -33,7 +38,7 @@ func (es *EnumSet) Remove(x uint64) {
 // Respond true if x is in the Enum Set.
 // Respond false if x is not in the Enum Set.
 func (es *EnumSet) In(x uint64) bool {
-	if x < es.max {
+	if inUniverse(x, es.max) {
 		v, ok := es.set[x]
 		if ok {
 			return v
@@ -41,3 +46,26 @@ func (es *EnumSet) In(x uint64) bool {
 	}
 	return false
 }
+
+// The maximum value of the EnumSet: members are below it.
+func (es *EnumSet) Max() uint64 {
+	return es.max
+}
+
+// Iterates over the members of the EnumSet in ascending order.
+func (es *EnumSet) Members() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		var xs []uint64
+		for x, v := range es.set {
+			if v && inUniverse(x, es.max) {
+				xs = append(xs, x)
+			}
+		}
+		slices.Sort(xs)
+		for _, x := range xs {
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
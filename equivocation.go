@@ -0,0 +1,111 @@
+package zks
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+)
+
+// ErrNoEquivocation is returned when checking evidence whose answers do not contradict each other.
+var ErrNoEquivocation = errors.New("zks: answers do not contradict each other")
+
+// Evidence that a prover equivocated: two answers on the same element that both verify under the same
+// commitment but claim different membership or reach different leaves.
+// An honest prover answers every query on an element identically, so the evidence is self-contained:
+// anyone trusting the parameters can check it with VerifyEvidence.
+//
+// Signed holds the signed commitment the answers were checked against, if any, tying the evidence to the prover's key.
+type Evidence struct {
+	Signed  *SignedCommitment `json:"signed,omitempty"`
+	Com     Com               `json:"commitment"`
+	X       uint64            `json:"x"`
+	Answers [2]*Answer        `json:"answers"`
+}
+
+// An answer a verifier collected, to be cross-checked with the answers collected by others.
+type Observation struct {
+	X      uint64  `json:"x"`
+	Answer *Answer `json:"answer"`
+}
+
+// Reports whether two answers on the same element contradict each other.
+func conflicting(a1 *Answer, a2 *Answer) bool {
+	if a1.answer != a2.answer || a1.levels != a2.levels || a1.arity != a2.arity {
+		return true
+	}
+	if a1.levels == 0 {
+		return false
+	}
+	return !a1.xcoms[a1.levels].Equals(*a2.xcoms[a2.levels])
+}
+
+// Input: The public parameters (h,ps), a ZKS commitment, an element x and two answers on x.
+// Return: Evidence of equivocation if both answers verify and contradict each other, nil otherwise.
+func DetectEquivocation(pp *PubVerPar, com Com, x uint64, a1 *Answer, a2 *Answer) *Evidence {
+	if !Vfy(pp, com, x, a1) || !Vfy(pp, com, x, a2) || !conflicting(a1, a2) {
+		return nil
+	}
+	return &Evidence{nil, com, x, [2]*Answer{a1, a2}}
+}
+
+// Like DetectEquivocation, for answers checked against a signed commitment whose signature verifies under pub.
+// The evidence holds the signed commitment, so it proves which prover equivocated.
+func DetectSignedEquivocation(pp *PubVerPar, pub ed25519.PublicKey, sc *SignedCommitment, x uint64, a1 *Answer, a2 *Answer) *Evidence {
+	if !VfySigned(pp, pub, sc, x, a1) || !VfySigned(pp, pub, sc, x, a2) {
+		return nil
+	}
+	ev := DetectEquivocation(pp, sc.Com, x, a1, a2)
+	if ev != nil {
+		ev.Signed = sc
+	}
+	return ev
+}
+
+// Checks evidence of equivocation under trusted public parameters.
+// Signed evidence is also checked against the public key pub of the prover, which is ignored otherwise.
+// Returns nil if the evidence proves the prover equivocated, an error otherwise.
+func VerifyEvidence(pp *PubVerPar, pub ed25519.PublicKey, ev *Evidence) error {
+	a1, a2 := ev.Answers[0], ev.Answers[1]
+	if ev.Signed != nil {
+		if err := VerifySigned(pub, ev.Signed); err != nil {
+			return err
+		}
+		if !ev.Signed.Com.Equals(ev.Com) {
+			return fmt.Errorf("zks: evidence commitment differs from the signed one")
+		}
+		if !ev.Signed.shapes(a1) || !ev.Signed.shapes(a2) {
+			return fmt.Errorf("zks: evidence answers are not of the signed depth and arity")
+		}
+	}
+	for i, a := range ev.Answers {
+		if !Vfy(pp, ev.Com, ev.X, a) {
+			return fmt.Errorf("zks: evidence answer %d for %d does not verify", i, ev.X)
+		}
+	}
+	if !conflicting(a1, a2) {
+		return ErrNoEquivocation
+	}
+	return nil
+}
+
+// Cross-checks the answers collected by one or more verifiers under the same commitment.
+// Answers that don't verify are ignored. Every verifying answer is compared with the first verifying answer on
+// the same element, and every contradiction is returned as evidence.
+func CrossCheck(pp *PubVerPar, com Com, obs []Observation) []*Evidence {
+	first := make(map[uint64]*Answer)
+	var evidence []*Evidence
+	for _, o := range obs {
+		if !Vfy(pp, com, o.X, o.Answer) {
+			continue
+		}
+		a, ok := first[o.X]
+		if !ok {
+			first[o.X] = o.Answer
+			continue
+		}
+		if conflicting(a, o.Answer) {
+			evidence = append(evidence, &Evidence{nil, com, o.X, [2]*Answer{a, o.Answer}})
+		}
+	}
+	return evidence
+}
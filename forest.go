@@ -0,0 +1,185 @@
+package zks
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/smarky7cd/ZKS/verify"
+)
+
+// ErrSetNamesCollide is returned when two set names of a forest hash to the same key.
+var ErrSetNamesCollide = errors.New("zks: set names collide")
+
+// Domain string used to derive the PRF keys of a forest.
+const forestDomain = "ZKS forest v1"
+
+// A forest holds many named sets under one commitment.
+//
+// Every set is a ZKS of its own. The roots of the sets are committed in a top-level ZKS keyed by the hash of
+// the set name, whose member leaves commit to the name and the root of their set rather than to their node ID.
+// Answers are two-staged: the path to the set in the top-level tree, then the answer in the set.
+//
+// The top-level tree and every set use a PRF key derived from the prover key and the set name, so the
+// nodes of different sets are independent and answers in one set reveal nothing about another.
+type Forest struct {
+	pp   *PubVerPar
+	top  *Repr
+	sets map[string]*forestSet
+}
+
+// A set of a forest: the parameters with its derived PRF key, its representation and its commitment.
+type forestSet struct {
+	pp   *PubVerPar
+	repr *Repr
+	com  Com
+}
+
+// An answer in a forest: the path to the set in the top-level tree and, if the forest holds the set,
+// the commitment to the set and the answer in it.
+type ForestAnswer struct {
+	set    bool
+	path   *Answer
+	com    Com
+	answer *Answer
+}
+
+// Derives the parameters with the PRF key for a part of a forest from the prover key.
+func (pp *PubVerPar) deriveForest(label string, name string) *PubVerPar {
+	return pp.derive(forestDomain, label, name)
+}
+
+// The key of a named set in the top-level tree of a forest.
+func forestKey(name string) uint64 {
+	return HashKey(name, math.MaxUint64)
+}
+
+// The message the top-level leaf of a named set commits to: the name and the commitment to the set.
+func forestLeaf(name string, com Com) []byte {
+	msg := binary.AppendUvarint([]byte("forest set"), uint64(len(name)))
+	msg = append(msg, name...)
+	msg = append(msg, com.c0.Bytes()...)
+	return append(msg, com.c1.Bytes()...)
+}
+
+// Input: public parameters (h,ps), the named membership sources and the options shaping the trees.
+// Return: the forest and the commitment to it, or an error if a set is invalid or two names collide.
+// The options apply to every set; the top-level tree only takes their arity.
+func NewForest(pp *PubVerPar, sets map[string]MembershipSource, opts TreeOptions) (*Forest, Com, error) {
+	if pp.kh == nil {
+		return nil, Com{}, ErrNoProverKey
+	}
+
+	f := &Forest{pp: pp.deriveForest("top", ""), sets: make(map[string]*forestSet)}
+	names := make(map[uint64]string)
+	for name, src := range sets {
+		key := forestKey(name)
+		if _, ok := names[key]; ok {
+			return nil, Com{}, ErrSetNamesCollide
+		}
+		names[key] = name
+
+		spp := pp.deriveForest("set", name)
+		repr, com, err := RepWithOptions(spp, src, opts)
+		if err != nil {
+			return nil, Com{}, fmt.Errorf("zks: set %q: %w", name, err)
+		}
+		f.sets[name] = &forestSet{spp, repr, com}
+	}
+
+	keys := make([]uint64, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	src, err := NewSortedSet(keys, math.MaxUint64)
+	if err != nil {
+		return nil, Com{}, err
+	}
+	levels, arity, err := treeShape(math.MaxUint64, TreeOptions{Arity: opts.Arity})
+	if err != nil {
+		return nil, Com{}, err
+	}
+	tree, err := buildTree(f.pp, src, levels, arity, func(x uint64, level uint64) []byte {
+		return forestLeaf(names[x], f.sets[names[x]].com)
+	})
+	if err != nil {
+		return nil, Com{}, err
+	}
+
+	f.top = &Repr{*tree, math.MaxUint64}
+	com := Com{tree.root.c0, tree.root.c1}
+	if err := pp.Publish(com); err != nil {
+		return nil, Com{}, err
+	}
+	return f, com, nil
+}
+
+// The names of the sets of the forest in ascending order.
+func (f *Forest) Names() []string {
+	names := make([]string, 0, len(f.sets))
+	for name := range f.sets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// The representation of a named set and the commitment to it, false if the forest holds no such set.
+func (f *Forest) Set(name string) (*Repr, Com, bool) {
+	s, ok := f.sets[name]
+	if !ok {
+		return nil, Com{}, false
+	}
+	return s.repr, s.com, true
+}
+
+// Input: the name of a set and an element x.
+// Return: ForestAnswer for x in the named set, nil if x is beyond the leaves of the set.
+// A set the forest does not hold is answered with the path proving its absence: it contains no element.
+func (f *Forest) Qry(name string, x uint64) *ForestAnswer {
+	key := forestKey(name)
+	s, ok := f.sets[name]
+	if !ok && f.top.tree.member(key) {
+		// the name collides with a set of the forest, whose leaf can't prove its absence
+		return nil
+	}
+	path := Qry(f.pp, f.top, key)
+	if !ok {
+		return &ForestAnswer{set: false, path: path}
+	}
+
+	a := Qry(s.pp, s.repr, x)
+	if a == nil {
+		return nil
+	}
+	return &ForestAnswer{true, path, s.com, a}
+}
+
+// Input: The public parameters (h), the commitment to a forest, the name of a set, an element x that was queried and the answer.
+// Return: True if the answer verifies, false otherwise.
+func VfyForest(pp *PubVerPar, com Com, name string, x uint64, answer *ForestAnswer) bool {
+	if answer == nil || answer.path == nil {
+		return false
+	}
+	key := forestKey(name)
+	if !answer.set {
+		return !answer.path.answer && VerifyPath(pp, com, key, answer.path)
+	}
+	return answer.path.answer &&
+		verify.VerifyOpenMessage(pp.verifiable(), com.verifiable(), key, answer.path.verifiable(), forestLeaf(name, answer.com)) &&
+		Vfy(pp, answer.com, x, answer.answer)
+}
+
+// The set-membership response of the answer: whether the forest holds the set and x is in it.
+// Only meaningful once the answer verifies.
+func (a *ForestAnswer) Member() bool {
+	return a.set && a.answer.Member()
+}
+
+// Reports whether the forest holds the set the answer is for. Only meaningful once the answer verifies.
+func (a *ForestAnswer) HasSet() bool {
+	return a.set
+}
@@ -0,0 +1,82 @@
+package zks
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// Domain string of the signature on a fresh answer.
+const freshDomain = "ZKS fresh answer v1"
+
+// An answer bound to the epoch of a signed commitment and to a nonce chosen by the verifier.
+//
+// Answers to an old set still verify under its old commitment, so a man-in-the-middle can replay them once the set
+// has changed. The prover signs the digest of the signed commitment (and so its epoch), the element, the answer and
+// the verifier's nonce together: a replayed answer carries a stale epoch or another nonce.
+type FreshAnswer struct {
+	Signed    *SignedCommitment `json:"signed"`
+	Answer    *Answer           `json:"answer"`
+	Nonce     []byte            `json:"nonce"`
+	Signature []byte            `json:"signature"`
+}
+
+// Requirements on a fresh answer.
+// MinEpoch is the oldest epoch accepted. A non-nil Nonce must match the nonce the answer is bound to.
+type FreshOptions struct {
+	MinEpoch uint64
+	Nonce    []byte
+}
+
+// Draws a fresh 16-byte nonce for a query.
+func NewNonce() []byte {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	return nonce
+}
+
+// Encodes the signed fields of a fresh answer on x.
+func (fa *FreshAnswer) message(x uint64) []byte {
+	digest := sha256.Sum256(fa.Signed.message())
+	answer, _ := fa.Answer.MarshalBinary()
+	adigest := sha256.Sum256(answer)
+
+	buf := binary.BigEndian.AppendUint64(nil, uint64(len(freshDomain)))
+	buf = append(buf, freshDomain...)
+	buf = append(buf, digest[:]...)
+	buf = binary.BigEndian.AppendUint64(buf, x)
+	buf = append(buf, adigest[:]...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(fa.Nonce)))
+	return append(buf, fa.Nonce...)
+}
+
+// Input: The public parameters (h,ps), a ZKS representation, the private key of the prover, the signed commitment to the representation,
+// an element x and the nonce sent by the verifier.
+// Return: the answer on x bound to the epoch of the signed commitment and to the nonce, nil if x is beyond the universe.
+func QryFresh(pp *PubVerPar, repr *Repr, priv ed25519.PrivateKey, sc *SignedCommitment, x uint64, nonce []byte) *FreshAnswer {
+	answer := Qry(pp, repr, x)
+	if answer == nil {
+		return nil
+	}
+	fa := &FreshAnswer{sc, answer, nonce, nil}
+	fa.Signature = ed25519.Sign(priv, fa.message(x))
+	return fa
+}
+
+// Input: The public parameters (h,ps), the public key of the prover, an element x that was queried, the fresh answer and the requirements on it.
+// Return: True if the answer verifies under a commitment signed by the prover in an epoch no older than opts.MinEpoch,
+// and is bound to opts.Nonce by the prover's signature, false otherwise.
+func VfyFresh(pp *PubVerPar, pub ed25519.PublicKey, x uint64, fa *FreshAnswer, opts FreshOptions) bool {
+	if fa == nil || fa.Signed == nil || !VfySigned(pp, pub, fa.Signed, x, fa.Answer) {
+		return false
+	}
+	if fa.Signed.Epoch < opts.MinEpoch {
+		return false
+	}
+	if opts.Nonce != nil && !bytes.Equal(fa.Nonce, opts.Nonce) {
+		return false
+	}
+	return ed25519.Verify(pub, fa.message(x), fa.Signature)
+}
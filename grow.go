@@ -0,0 +1,200 @@
+package zks
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+
+	mc "github.com/smarky7CD/go-dl-mercurial-commitments"
+	"github.com/smarky7cd/ZKS/verify"
+)
+
+// ErrUniverseShrinks is returned when growing a universe to a smaller bound.
+var ErrUniverseShrinks = errors.New("zks: a universe can only grow")
+
+// A tree is grown by adding levels above its root, which becomes the leftmost node of the new level it lands on.
+// The nodes of the original tree and their commitments are unchanged, so the new root commits to the old one
+// and old answers are extended into answers under the new root by the path linking both (a GrowthProof).
+//
+// Every growth is a region of the tree: the nodes of the grown tree outside the trees it was grown from.
+// The nodes of a region are derived from the PRF in a domain of their own and at their level in the region,
+// and the leaves of members commit to their node ID at the depth of the region they were committed in.
+
+// The PRF domain of the nodes added when growing a tree to the given depth.
+func growDomain(levels uint64) []byte {
+	return binary.AppendUvarint([]byte("grow"), levels)
+}
+
+// Computes the region of node i at a level of a tree of the given depth grown from the given depths.
+// Return: the level of the node within its region and the PRF domain of the region, nil for the original tree.
+func region(i uint64, level uint64, levels uint64, arity uint64, grown []uint64) (uint64, []byte) {
+	for g, depth := range grown {
+		offset := levels - depth
+		if level >= offset && inCapacity(i, level-offset, arity) {
+			if g == 0 {
+				return level - offset, nil
+			}
+			return level - offset, growDomain(depth)
+		}
+	}
+	if len(grown) == 0 {
+		return level, nil
+	}
+	return level, growDomain(levels)
+}
+
+// The level of the leaf of x within its region, i.e. the depth of the tree x was committed in.
+func (tree *Tree) leafLevel(x uint64) uint64 {
+	level, _ := region(x, tree.levels, tree.levels, tree.arity, tree.grown)
+	return level
+}
+
+// Computes a hard commitment to msg for node x at a level of the tree, within its region.
+func (tree *Tree) hardNode(pp *PubVerPar, x uint64, level uint64, msg []byte) *TreeNode {
+	l, domain := region(x, level, tree.levels, tree.arity, tree.grown)
+	r0, r1 := deriveRandomnessIn(pp, domain, x, l)
+	c0, c1 := mc.HardCommit(&pp.h, msg, &r0, &r1)
+	return NewNode(false, c0, c1, r0, r1)
+}
+
+// Computes a soft commitment for node x at a level of the tree, within its region.
+func (tree *Tree) softNode(pp *PubVerPar, x uint64, level uint64) *TreeNode {
+	l, domain := region(x, level, tree.levels, tree.arity, tree.grown)
+	r0, r1 := deriveRandomnessIn(pp, domain, x, l)
+	c0, c1 := mc.SoftCommit(&r0, &r1)
+	return NewNode(true, c0, c1, r0, r1)
+}
+
+// Grows the tree to the given depth, adding empty levels above its root.
+// The tree itself is not modified: the grown tree shares its nodes.
+func (tree *Tree) grow(pp *PubVerPar, levels uint64) *Tree {
+	if levels <= tree.levels {
+		return tree
+	}
+	d := levels - tree.levels
+	g := &Tree{
+		tree:   make(map[uint64]map[uint64]*TreeNode),
+		levels: levels,
+		arity:  tree.arity,
+		grown:  append(slices.Clip(tree.grown), tree.levels),
+	}
+	for j, layer := range tree.tree {
+		g.tree[j+d] = layer
+	}
+
+	// the old root and its new siblings, then the path of hard nodes up to the new root
+	g.tree[d] = map[uint64]*TreeNode{0: tree.tree[0][0]}
+	for j := d; j >= 1; j-- {
+		for k := uint64(1); k < g.arity; k++ {
+			g.tree[j][k] = g.softNode(pp, k, j)
+		}
+		g.tree[j-1] = map[uint64]*TreeNode{0: g.hardNode(pp, 0, j-1, g.message(0, j-1))}
+	}
+	g.root = *g.tree[0][0]
+	return g
+}
+
+// A growth proof links the commitment of a tree to the commitment of the tree grown from it.
+// It opens the nodes of the new levels on the path to the old root, which reveal nothing but the growth.
+type GrowthProof struct {
+	link verify.Link
+}
+
+// Computes the growth proof linking the tree the tree was grown from, d levels below, to its root.
+// Only the new levels are materialised on the path to the old root: the old tree may hold no path to element 0.
+func (tree *Tree) growthProof(d uint64) *GrowthProof {
+	link := verify.Link{Arity: tree.arity}
+	for j := uint64(1); j <= d; j++ {
+		step := verify.Step{Com: tree.tree[j][0].com().verifiable()}
+		if tree.arity == 2 {
+			step.Sib = tree.tree[j][1].com().verifiable()
+		} else {
+			step.VOpen = VectorOpen(groupComs(tree.tree[j], 0, tree.arity), 0)
+		}
+		link.Path = append(link.Path, step)
+	}
+	for j := uint64(0); j < d; j++ {
+		node := tree.tree[j][0]
+		link.Opens = append(link.Opens, verify.Open{R0: node.r0, R1: node.r1})
+	}
+	return &GrowthProof{link}
+}
+
+// Input: The public parameters (h,ps) and the new universe bound, which must not be smaller than the current one.
+// Return: the grown ZKS representation, its commitment and the proof linking the current commitment to it.
+//
+// The current tree becomes the leftmost subtree of the grown one, so its commitment and its answers remain valid:
+// GrowthProof.Extend turns them into answers under the new commitment. A bound that fits the current depth only
+// raises the bound, with an empty proof. The new part of the universe is empty.
+func (repr *Repr) GrowUniverse(pp *PubVerPar, max uint64) (*Repr, Com, *GrowthProof, error) {
+	if pp.kh == nil {
+		return nil, Com{}, nil, ErrNoProverKey
+	}
+	if max < repr.max {
+		return nil, Com{}, nil, ErrUniverseShrinks
+	}
+
+	levels := ComputeDepth(max, repr.tree.arity)
+	if levels < repr.tree.levels {
+		levels = repr.tree.levels
+	}
+	tree := repr.tree.grow(pp, levels)
+	proof := tree.growthProof(levels - repr.tree.levels)
+	com := Com{tree.root.c0, tree.root.c1}
+	if err := pp.Publish(com); err != nil {
+		return nil, Com{}, nil, err
+	}
+	return &Repr{*tree, max}, com, proof, nil
+}
+
+// Input: The public parameters (h), the commitment of a tree, the commitment of the tree grown from it and the growth proof.
+// Return: True if the grown tree holds the tree of the old commitment as its leftmost subtree, false otherwise.
+func VerifyGrowth(pp *PubVerPar, old Com, com Com, proof *GrowthProof) bool {
+	if proof == nil {
+		return false
+	}
+	return verify.VerifyLink(pp.verifiable(), old.verifiable(), com.verifiable(), &proof.link)
+}
+
+// Extends an answer computed before the growth into the answer for the same element in the grown tree.
+// Return: the extended answer, which verifies under the new commitment if the original verifies under the old one,
+// nil if the answer is malformed.
+func (proof *GrowthProof) Extend(answer *Answer) *Answer {
+	v := proof.link.Extend(answer.verifiable())
+	if v == nil {
+		return nil
+	}
+	return answerFrom(v)
+}
+
+// The number of levels the proof grows the tree by.
+func (proof *GrowthProof) Levels() uint64 {
+	return uint64(len(proof.link.Path))
+}
+
+// Rebuilds the tree of a representation grown from the given depths, as recorded by a snapshot.
+// The members must be in the original tree.
+func buildGrownTree(pp *PubVerPar, src MembershipSource, levels uint64, arity uint64, grown []uint64) (*Tree, error) {
+	if !ValidArity(arity) {
+		return nil, ErrInvalidArity
+	}
+	for i, depth := range grown {
+		if depth >= levels || i > 0 && depth <= grown[i-1] {
+			return nil, ErrMalformed
+		}
+	}
+	for x := range src.Members() {
+		if !inCapacity(x, grown[0], arity) {
+			return nil, ErrMalformed
+		}
+	}
+
+	tree, err := buildTree(pp, src, grown[0], arity, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, depth := range append(slices.Clip(grown[1:]), levels) {
+		tree = tree.grow(pp, depth)
+	}
+	return tree, nil
+}
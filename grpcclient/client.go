@@ -0,0 +1,125 @@
+// Package grpcclient queries a ZKS prover over gRPC and verifies every answer.
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/smarky7cd/ZKS/zkspb"
+	"google.golang.org/grpc"
+)
+
+// ErrVerification is returned when an answer does not verify against the pinned commitment.
+var ErrVerification = errors.New("grpcclient: answer does not verify against the pinned commitment")
+
+// A Client queries a prover and runs Vfy on every answer against a pinned commitment.
+type Client struct {
+	prover zkspb.ProverClient
+	pp     *zks.PubVerPar
+	com    zks.Com
+}
+
+// A commitment published by the prover at an epoch.
+type Epoch struct {
+	Epoch  uint64
+	Com    zks.Com
+	Params *zks.PubVerPar
+}
+
+// Creates a client over conn verifying answers with the verifier params pp against the pinned commitment com.
+func New(conn grpc.ClientConnInterface, pp *zks.PubVerPar, com zks.Com) *Client {
+	return &Client{zkspb.NewProverClient(conn), pp, com}
+}
+
+// Pins a new commitment, e.g. one received from WatchCommitments.
+func (c *Client) Pin(com zks.Com) {
+	c.com = com
+}
+
+// Decodes a commitment sent by the prover.
+func decodeEpoch(m *zkspb.Commitment) (*Epoch, error) {
+	e := &Epoch{Epoch: m.GetEpoch(), Params: new(zks.PubVerPar)}
+	if err := e.Com.UnmarshalBinary(m.GetCommitment()); err != nil {
+		return nil, err
+	}
+	if err := e.Params.UnmarshalBinary(m.GetParams()); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Fetches the current commitment and verifier params of the prover over conn.
+// They are not authenticated: compare them with ones obtained over a trusted channel before pinning.
+func GetCommitment(ctx context.Context, conn grpc.ClientConnInterface) (*Epoch, error) {
+	m, err := zkspb.NewProverClient(conn).GetCommitment(ctx, &zkspb.GetCommitmentRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return decodeEpoch(m)
+}
+
+// Decodes and verifies an answer on x.
+func (c *Client) verify(x uint64, data []byte) (bool, error) {
+	var a zks.Answer
+	if err := a.UnmarshalBinary(data); err != nil {
+		return false, err
+	}
+	if !zks.Vfy(c.pp, c.com, x, &a) {
+		return false, ErrVerification
+	}
+	return a.Member(), nil
+}
+
+// Asks the prover whether x is in the set.
+// Returns the verified answer, or ErrVerification if the answer does not verify.
+func (c *Client) Query(ctx context.Context, x uint64) (bool, error) {
+	resp, err := c.prover.Query(ctx, &zkspb.QueryRequest{X: x})
+	if err != nil {
+		return false, err
+	}
+	return c.verify(x, resp.GetAnswer())
+}
+
+// Asks the prover whether each of xs is in the set.
+// Returns the verified answers in order, or ErrVerification if any answer does not verify.
+func (c *Client) QueryBatch(ctx context.Context, xs []uint64) ([]bool, error) {
+	resp, err := c.prover.QueryBatch(ctx, &zkspb.QueryBatchRequest{Xs: xs})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.GetAnswers()) != len(xs) {
+		return nil, fmt.Errorf("grpcclient: got %d answers for %d elements", len(resp.GetAnswers()), len(xs))
+	}
+
+	members := make([]bool, len(xs))
+	for i, x := range xs {
+		if members[i], err = c.verify(x, resp.GetAnswers()[i]); err != nil {
+			return nil, err
+		}
+	}
+	return members, nil
+}
+
+// Calls fn with the current commitment of the prover and then with every new one, until ctx is done or fn fails.
+// The commitments are not authenticated; fn decides whether to Pin them.
+func (c *Client) WatchCommitments(ctx context.Context, fn func(*Epoch) error) error {
+	stream, err := c.prover.WatchCommitments(ctx, &zkspb.WatchCommitmentsRequest{})
+	if err != nil {
+		return err
+	}
+	for {
+		m, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		e, err := decodeEpoch(m)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
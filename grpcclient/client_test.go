@@ -0,0 +1,111 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/smarky7cd/ZKS/grpcserver"
+	"github.com/smarky7cd/ZKS/zkspb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Starts a prover on a local port and returns a connection to it.
+func startProver(t *testing.T, srv *grpcserver.Server) *grpc.ClientConn {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	gs := grpc.NewServer()
+	zkspb.RegisterProverServer(gs, srv)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestEndToEnd(t *testing.T) {
+	values := map[uint64]bool{2: true, 3: true, 17: true, 30: true}
+	pp := zks.Gen()
+	repr, com := zks.Rep(pp, zks.NewEnumSet(values, 32))
+	srv, err := grpcserver.New(pp, repr, com)
+	assert.Nil(t, err)
+	conn := startProver(t, srv)
+	ctx := context.Background()
+
+	e, err := GetCommitment(ctx, conn)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), e.Epoch)
+	assert.True(t, e.Com.Equals(com))
+
+	c := New(conn, e.Params, e.Com)
+	for x := uint64(0); x < 32; x++ {
+		member, err := c.Query(ctx, x)
+		assert.Nil(t, err)
+		assert.Equal(t, values[x], member, "answer for %d", x)
+	}
+
+	xs := []uint64{0, 2, 3, 4, 17, 31, 30}
+	members, err := c.QueryBatch(ctx, xs)
+	assert.Nil(t, err)
+	for i, x := range xs {
+		assert.Equal(t, values[x], members[i], "answer for %d", x)
+	}
+
+	_, err = c.Query(ctx, 32)
+	assert.Equal(t, codes.OutOfRange, status.Code(err), "no proof beyond the leaves")
+	_, err = c.QueryBatch(ctx, make([]uint64, grpcserver.MaxBatch+1))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestWatchCommitments(t *testing.T) {
+	pp := zks.Gen()
+	repr, com := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{5: true}, 16))
+	log, err := zks.OpenLog(filepath.Join(t.TempDir(), "coms.log"))
+	assert.Nil(t, err)
+	defer log.Close()
+	srv, err := grpcserver.New(pp.WithLog(log), repr, com)
+	assert.Nil(t, err)
+	conn := startProver(t, srv)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := New(conn, pp, com)
+	member, err := c.Query(ctx, 5)
+	assert.Nil(t, err)
+	assert.True(t, member)
+
+	epochs := make(chan *Epoch)
+	go c.WatchCommitments(ctx, func(e *Epoch) error {
+		epochs <- e
+		return nil
+	})
+	e := <-epochs
+	assert.Equal(t, uint64(1), e.Epoch)
+	assert.True(t, e.Com.Equals(com))
+
+	// once the server publishes another set, answers no longer verify against the pinned commitment
+	repr2, com2 := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{6: true}, 16))
+	assert.Nil(t, srv.Update(repr2, com2))
+	assert.Equal(t, uint64(2), log.Size())
+	e = <-epochs
+	assert.Equal(t, uint64(2), e.Epoch)
+	assert.True(t, e.Com.Equals(com2))
+
+	_, err = c.Query(ctx, 5)
+	assert.ErrorIs(t, err, ErrVerification)
+	_, err = c.QueryBatch(ctx, []uint64{5, 6})
+	assert.ErrorIs(t, err, ErrVerification)
+
+	c.Pin(e.Com)
+	member, err = c.Query(ctx, 6)
+	assert.Nil(t, err)
+	assert.True(t, member)
+}
@@ -0,0 +1,124 @@
+// Package grpcserver implements the zkspb.Prover gRPC service on top of a ZKS representation.
+package grpcserver
+
+import (
+	"context"
+	"sync"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/smarky7cd/ZKS/zkspb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxBatch is the largest number of elements a batch query may ask for.
+const MaxBatch = 1024
+
+// A Server answers queries on the ZKS it currently publishes.
+// Every Update publishes a new epoch, which is streamed to the clients watching commitments.
+type Server struct {
+	zkspb.UnimplementedProverServer
+
+	pp      *zks.PubVerPar
+	mu      sync.RWMutex
+	repr    *zks.Repr
+	com     zks.Com
+	epoch   uint64
+	changed chan struct{}
+}
+
+// Creates a server publishing the commitment com to the representation repr as epoch 1.
+// Returns an error if the commitment can't be appended to the log of pp (see zks.PubVerPar.WithLog).
+func New(pp *zks.PubVerPar, repr *zks.Repr, com zks.Com) (*Server, error) {
+	if err := pp.Publish(com); err != nil {
+		return nil, err
+	}
+	return &Server{pp: pp, repr: repr, com: com, epoch: 1, changed: make(chan struct{})}, nil
+}
+
+// Publishes a new representation and its commitment as the next epoch.
+// The server stays at the current epoch if the commitment can't be appended to the log of its parameters.
+func (s *Server) Update(repr *zks.Repr, com zks.Com) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.pp.Publish(com); err != nil {
+		return err
+	}
+	s.repr, s.com = repr, com
+	s.epoch++
+
+	// wake up the watchers
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return nil
+}
+
+// The current epoch, its representation and commitment, and a channel closed when they change.
+func (s *Server) current() (uint64, *zks.Repr, zks.Com, <-chan struct{}) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.epoch, s.repr, s.com, s.changed
+}
+
+// Encodes the commitment of an epoch.
+func (s *Server) commitment(epoch uint64, com zks.Com) *zkspb.Commitment {
+	c, _ := com.MarshalBinary()
+	pp, _ := s.pp.MarshalBinary()
+	return &zkspb.Commitment{Epoch: epoch, Commitment: c, Params: pp}
+}
+
+// Answers a query on x with the encoded answer.
+func (s *Server) query(repr *zks.Repr, x uint64) ([]byte, error) {
+	a := zks.Qry(s.pp, repr, x)
+	if a == nil {
+		return nil, status.Errorf(codes.OutOfRange, "element %d is beyond the universe", x)
+	}
+	return a.MarshalBinary()
+}
+
+func (s *Server) GetCommitment(ctx context.Context, req *zkspb.GetCommitmentRequest) (*zkspb.Commitment, error) {
+	epoch, _, com, _ := s.current()
+	return s.commitment(epoch, com), nil
+}
+
+func (s *Server) Query(ctx context.Context, req *zkspb.QueryRequest) (*zkspb.QueryResponse, error) {
+	epoch, repr, _, _ := s.current()
+	a, err := s.query(repr, req.GetX())
+	if err != nil {
+		return nil, err
+	}
+	return &zkspb.QueryResponse{Epoch: epoch, Answer: a}, nil
+}
+
+func (s *Server) QueryBatch(ctx context.Context, req *zkspb.QueryBatchRequest) (*zkspb.QueryBatchResponse, error) {
+	if len(req.GetXs()) > MaxBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "batch of %d elements exceeds %d", len(req.GetXs()), MaxBatch)
+	}
+
+	// answer the whole batch from the same epoch
+	epoch, repr, _, _ := s.current()
+	resp := &zkspb.QueryBatchResponse{Epoch: epoch}
+	for _, x := range req.GetXs() {
+		a, err := s.query(repr, x)
+		if err != nil {
+			return nil, err
+		}
+		resp.Answers = append(resp.Answers, a)
+	}
+	return resp, nil
+}
+
+func (s *Server) WatchCommitments(req *zkspb.WatchCommitmentsRequest, stream zkspb.Prover_WatchCommitmentsServer) error {
+	for {
+		epoch, _, com, changed := s.current()
+		if err := stream.Send(s.commitment(epoch, com)); err != nil {
+			return err
+		}
+
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Package keyed commits to sets of typed keys (strings, UUIDs, IP addresses, digests, signed integers).
+//
+// A KeyEncoder maps keys into the universe of a ZKS. The generic Rep, Qry and Vfy apply the same encoder on
+// the prover and verifier side, so both agree on the element a key stands for:
+//
+//	repr, com, err := keyed.Rep(pp, keyed.String[string]{}, slices.Values(emails), zks.TreeOptions{})
+//	answer, err := keyed.Qry(pp, repr, keyed.String[string]{}, "alice@example.com")
+//	ok := keyed.Vfy(pp, com, keyed.String[string]{}, "alice@example.com", answer)
+//
+// Encoders of keys wider than 64 bits hash them into the universe. Distinct keys then collide with
+// probability about n^2/2^65 for n keys, and Rep fails with zks.ErrKeysCollide if two keys of the set do.
+package keyed
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"net/netip"
+
+	zks "github.com/smarky7cd/ZKS"
+)
+
+// ErrKey is returned when a key can't be encoded, e.g. an IPv6 address given to the IPv4 encoder.
+var ErrKey = errors.New("keyed: key can't be encoded")
+
+// A KeyEncoder maps keys of type K to elements of a universe of Max values.
+type KeyEncoder[K any] interface {
+	Max() uint64
+	Encode(k K) (uint64, error)
+}
+
+// Encoders hashing keys into the universe report the bytes they hash, so Rep can tell distinct keys colliding
+// from the same key given twice.
+type hashingEncoder[K any] interface {
+	hashed(k K) []byte
+}
+
+// The universe bound of the hashing encoders, every uint64 (see zks.MembershipSource).
+// Keys hash modulo the bound, as zks.HashKey does, so they never hash to the largest uint64.
+const hashedMax = math.MaxUint64
+
+// Hashes a byte key into the universe of the hashing encoders.
+func hashBytes(b []byte) uint64 {
+	return zks.HashKey(string(b), hashedMax)
+}
+
+// Encodes strings by hashing them (zks.HashKey), as the zks loaders do with Hashed keys.
+type String[K ~string] struct{}
+
+func (String[K]) Max() uint64 {
+	return hashedMax
+}
+
+func (String[K]) Encode(k K) (uint64, error) {
+	return zks.HashKey(string(k), hashedMax), nil
+}
+
+func (String[K]) hashed(k K) []byte {
+	return []byte(k)
+}
+
+// Encodes 16-byte UUIDs (e.g. github.com/google/uuid.UUID) by hashing them.
+type UUID[K ~[16]byte] struct{}
+
+func (UUID[K]) Max() uint64 {
+	return hashedMax
+}
+
+func (UUID[K]) Encode(k K) (uint64, error) {
+	b := [16]byte(k)
+	return hashBytes(b[:]), nil
+}
+
+func (UUID[K]) hashed(k K) []byte {
+	b := [16]byte(k)
+	return b[:]
+}
+
+// Encodes IPv4 addresses (IPv4-mapped IPv6 addresses included) exactly, into a universe of 2^32 values.
+type IPv4 struct{}
+
+func (IPv4) Max() uint64 {
+	return 1 << 32
+}
+
+func (IPv4) Encode(k netip.Addr) (uint64, error) {
+	k = k.Unmap()
+	if !k.Is4() {
+		return 0, fmt.Errorf("%w: %v is not an IPv4 address", ErrKey, k)
+	}
+	b := k.As4()
+	return uint64(binary.BigEndian.Uint32(b[:])), nil
+}
+
+// Encodes IP addresses by hashing their 16-byte form, so an IPv4 address and its IPv4-mapped IPv6 form are the same key.
+type IPv6 struct{}
+
+func (IPv6) Max() uint64 {
+	return hashedMax
+}
+
+func (IPv6) Encode(k netip.Addr) (uint64, error) {
+	if !k.IsValid() {
+		return 0, fmt.Errorf("%w: invalid IP address", ErrKey)
+	}
+	b := k.As16()
+	return hashBytes(b[:]), nil
+}
+
+func (IPv6) hashed(k netip.Addr) []byte {
+	b := k.As16()
+	return b[:]
+}
+
+// Encodes SHA-256 digests by their first 8 bytes, which are already uniformly distributed.
+type SHA256[K ~[32]byte] struct{}
+
+func (SHA256[K]) Max() uint64 {
+	return hashedMax
+}
+
+func (SHA256[K]) Encode(k K) (uint64, error) {
+	return binary.BigEndian.Uint64(k[:8]) % hashedMax, nil
+}
+
+func (SHA256[K]) hashed(k K) []byte {
+	return k[:]
+}
+
+// Encodes signed integers exactly and in order, by offsetting them by 2^63, into the universe of every uint64.
+type Int64[K ~int64] struct{}
+
+func (Int64[K]) Max() uint64 {
+	return math.MaxUint64
+}
+
+func (Int64[K]) Encode(k K) (uint64, error) {
+	return uint64(k) ^ 1<<63, nil
+}
+
+// Input: public parameters (h,ps), a key encoder, the keys of the set and the options shaping the tree.
+// Return: ZKS representation of the encoded keys and a commitment to it, or an error if a key can't be encoded,
+// two distinct keys hash to the same element (zks.ErrKeysCollide) or the options are invalid.
+func Rep[K any](pp *zks.PubVerPar, enc KeyEncoder[K], keys iter.Seq[K], opts zks.TreeOptions) (*zks.Repr, zks.Com, error) {
+	set := zks.NewBitmapSet(enc.Max())
+	hasher, hashing := enc.(hashingEncoder[K])
+	// the key each element was hashed from
+	hashedFrom := make(map[uint64]K)
+	for k := range keys {
+		x, err := enc.Encode(k)
+		if err != nil {
+			return nil, zks.Com{}, err
+		}
+		if hashing {
+			if prev, ok := hashedFrom[x]; ok && !bytes.Equal(hasher.hashed(prev), hasher.hashed(k)) {
+				return nil, zks.Com{}, fmt.Errorf("%w: %v and %v", zks.ErrKeysCollide, prev, k)
+			}
+			hashedFrom[x] = k
+		}
+		set.Add(x)
+	}
+	return zks.RepWithOptions(pp, set, opts)
+}
+
+// Input: public parameters (h,ps), a ZKS representation built by Rep, the key encoder it was built with and a key.
+// Return: Answer struct for the encoded key, or an error if the key can't be encoded.
+func Qry[K any](pp *zks.PubVerPar, repr *zks.Repr, enc KeyEncoder[K], k K) (*zks.Answer, error) {
+	x, err := enc.Encode(k)
+	if err != nil {
+		return nil, err
+	}
+	return zks.Qry(pp, repr, x), nil
+}
+
+// Input: public parameters (h,ps), a ZKS commitment, the key encoder the set was built with, a key that was queried and the answer.
+// Return: True if the answer verifies for the encoded key, false otherwise.
+func Vfy[K any](pp *zks.PubVerPar, com zks.Com, enc KeyEncoder[K], k K, answer *zks.Answer) bool {
+	x, err := enc.Encode(k)
+	return err == nil && zks.Vfy(pp, com, x, answer)
+}
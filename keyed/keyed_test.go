@@ -0,0 +1,93 @@
+package keyed
+
+import (
+	"crypto/sha256"
+	"math"
+	"net/netip"
+	"slices"
+	"testing"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/stretchr/testify/assert"
+)
+
+// Commits to members, then checks every member and non-member verifies with the right answer.
+func checkKeys[K any](t *testing.T, pp *zks.PubVerPar, enc KeyEncoder[K], members, others []K) {
+	repr, com, err := Rep(pp, enc, slices.Values(members), zks.TreeOptions{})
+	assert.Nil(t, err)
+	for _, keys := range []struct {
+		ks     []K
+		member bool
+	}{{members, true}, {others, false}} {
+		for _, k := range keys.ks {
+			answer, err := Qry(pp, repr, enc, k)
+			assert.Nil(t, err)
+			assert.True(t, Vfy(pp, com, enc, k, answer), "key %v", k)
+			assert.Equal(t, keys.member, answer.Member(), "key %v", k)
+		}
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	pp := zks.Gen()
+
+	checkKeys(t, pp, String[string]{}, []string{"alice", "bob", ""}, []string{"carol", "Alice"})
+	checkKeys(t, pp, UUID[[16]byte]{}, [][16]byte{{1}, {2, 3}}, [][16]byte{{}, {3, 2}})
+	checkKeys(t, pp, IPv4{},
+		[]netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("255.255.255.255")},
+		[]netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("0.0.0.0")})
+	checkKeys(t, pp, IPv6{},
+		[]netip.Addr{netip.MustParseAddr("2001:db8::1"), netip.MustParseAddr("10.0.0.1")},
+		[]netip.Addr{netip.MustParseAddr("2001:db8::2"), netip.MustParseAddr("::1")})
+	checkKeys(t, pp, SHA256[[32]byte]{}, [][32]byte{sha256.Sum256([]byte("a"))}, [][32]byte{sha256.Sum256([]byte("b"))})
+	checkKeys(t, pp, Int64[int64]{}, []int64{-1, 0, math.MinInt64, 7, math.MaxInt64}, []int64{1, -7, math.MaxInt64 - 1})
+	checkKeys(t, pp, Int64[int64]{}, []int64{math.MaxInt64}, []int64{math.MinInt64, math.MinInt64 + 1, math.MaxInt64 - 1})
+}
+
+func TestEncodings(t *testing.T) {
+	// IPv4 is exact, and IPv4-mapped addresses are the same key
+	x, err := IPv4{}.Encode(netip.MustParseAddr("1.2.3.4"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0x01020304), x)
+	y, _ := IPv4{}.Encode(netip.MustParseAddr("::ffff:1.2.3.4"))
+	assert.Equal(t, x, y)
+	_, err = IPv4{}.Encode(netip.MustParseAddr("2001:db8::1"))
+	assert.ErrorIs(t, err, ErrKey)
+	_, err = IPv6{}.Encode(netip.Addr{})
+	assert.ErrorIs(t, err, ErrKey)
+
+	// Int64 preserves order over the whole int64 range
+	lo, _ := Int64[int64]{}.Encode(math.MinInt64)
+	mid, _ := Int64[int64]{}.Encode(0)
+	hi, err := Int64[int64]{}.Encode(math.MaxInt64)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), lo)
+	assert.Equal(t, uint64(1<<63), mid)
+	assert.Equal(t, uint64(math.MaxUint64), hi)
+
+	// distinct keys hashing to the same element fail, the same key given twice doesn't
+	d1, d2 := sha256.Sum256([]byte("a")), sha256.Sum256([]byte("a"))
+	d2[31] ^= 1
+	_, _, err = Rep(zks.Gen(), SHA256[[32]byte]{}, slices.Values([][32]byte{d1, d2}), zks.TreeOptions{})
+	assert.ErrorIs(t, err, zks.ErrKeysCollide)
+	_, _, err = Rep(zks.Gen(), SHA256[[32]byte]{}, slices.Values([][32]byte{d1, d1}), zks.TreeOptions{})
+	assert.Nil(t, err)
+	_, _, err = Rep(zks.Gen(), IPv6{}, slices.Values([]netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("::ffff:10.0.0.1")}), zks.TreeOptions{})
+	assert.Nil(t, err)
+
+	// strings hash like the zks loaders
+	s, _ := String[string]{}.Encode("alice")
+	assert.Equal(t, zks.HashKey("alice", math.MaxUint64), s)
+
+	// an unencodable key fails on both sides
+	pp := zks.Gen()
+	repr, com, err := Rep(pp, IPv4{}, slices.Values([]netip.Addr{netip.MustParseAddr("::1")}), zks.TreeOptions{})
+	assert.ErrorIs(t, err, ErrKey)
+	assert.Nil(t, repr)
+	repr, com, _ = Rep(pp, IPv4{}, slices.Values([]netip.Addr{netip.MustParseAddr("1.2.3.4")}), zks.TreeOptions{})
+	_, err = Qry(pp, repr, IPv4{}, netip.MustParseAddr("::1"))
+	assert.ErrorIs(t, err, ErrKey)
+	answer, _ := Qry(pp, repr, IPv4{}, netip.MustParseAddr("1.2.3.4"))
+	assert.False(t, Vfy(pp, com, IPv4{}, netip.MustParseAddr("::1"), answer))
+	assert.False(t, Vfy(pp, com, IPv4{}, netip.MustParseAddr("1.2.3.5"), answer))
+}
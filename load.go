@@ -0,0 +1,273 @@
+package zks
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrKeysCollide is returned when distinct keys of a member list hash to the same member.
+var ErrKeysCollide = errors.New("zks: distinct keys hash to the same member")
+
+// Options for loading a set from a member list.
+//
+// Max is the universe bound every member must be below.
+// Column names the CSV column holding the members in the header row, the first column if empty.
+// Hashed reads the members as string keys and hashes them into the universe with HashKey.
+// Loading fails with ErrKeysCollide rather than merge two keys into one member.
+type LoadOptions struct {
+	Max    uint64
+	Column string
+	Hashed bool
+}
+
+// An error at a line of a member list.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Hashes a string key into a universe of max values: the first 8 bytes of its SHA-256 digest modulo max.
+// Distinct keys may collide, so the universe should be much larger than the number of keys (e.g. 2^64).
+func HashKey(key string, max uint64) uint64 {
+	d := sha256.Sum256([]byte(key))
+	x := binary.BigEndian.Uint64(d[:])
+	if max == 0 {
+		return x
+	}
+	return x % max
+}
+
+// Parses the members of a list under the options.
+// keys holds the key each hashed member was read from, to detect distinct keys colliding.
+type memberParser struct {
+	opts LoadOptions
+	keys map[uint64]string
+}
+
+func newMemberParser(opts LoadOptions) *memberParser {
+	return &memberParser{opts, make(map[uint64]string)}
+}
+
+// Parses a member of the list.
+func (p *memberParser) member(s string) (uint64, error) {
+	opts := &p.opts
+	if opts.Hashed {
+		if opts.Max == 0 {
+			return 0, fmt.Errorf("no universe to hash key %q into", s)
+		}
+		x := HashKey(s, opts.Max)
+		if key, ok := p.keys[x]; ok && key != s {
+			return 0, fmt.Errorf("%w: %q and %q", ErrKeysCollide, key, s)
+		}
+		p.keys[x] = s
+		return x, nil
+	}
+	x, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if !inUniverse(x, opts.Max) {
+		return 0, fmt.Errorf("member %d is not below the universe bound %d", x, opts.Max)
+	}
+	return x, nil
+}
+
+// Reads a newline-delimited member list into a set. Blank lines and lines starting with # are ignored.
+// Errors are *LineError naming the offending line.
+func ReadLines(r io.Reader, opts LoadOptions) (*BitmapSet, error) {
+	set, p := NewBitmapSet(opts.Max), newMemberParser(opts)
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		x, err := p.member(line)
+		if err != nil {
+			return nil, &LineError{n, err}
+		}
+		set.Add(x)
+	}
+	return set, sc.Err()
+}
+
+// Reads the members in a column of a CSV file with a header row into a set.
+// Empty cells are ignored. Errors are *LineError naming the offending line.
+func ReadCSV(r io.Reader, opts LoadOptions) (*BitmapSet, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	col := 0
+	if opts.Column != "" {
+		col = slices.IndexFunc(header, func(name string) bool { return strings.TrimSpace(name) == opts.Column })
+		if col < 0 {
+			return nil, fmt.Errorf("zks: no column %q in the CSV header", opts.Column)
+		}
+	}
+
+	set, p := NewBitmapSet(opts.Max), newMemberParser(opts)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return set, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if col >= len(record) {
+			return nil, &LineError{line, fmt.Errorf("no column %d", col)}
+		}
+		cell := strings.TrimSpace(record[col])
+		if cell == "" {
+			continue
+		}
+		x, err := p.member(cell)
+		if err != nil {
+			return nil, &LineError{line, err}
+		}
+		set.Add(x)
+	}
+}
+
+// Reads a JSON array of members into a set: integers, or strings with Hashed.
+// Errors are *LineError naming the offending line.
+func ReadJSON(r io.Reader, opts LoadOptions) (*BitmapSet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	lineAt := func(offset int64) int {
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, &LineError{lineAt(dec.InputOffset()), errors.New("expected a JSON array of members")}
+	}
+
+	set, p := NewBitmapSet(opts.Max), newMemberParser(opts)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, &LineError{lineAt(dec.InputOffset()), err}
+		}
+
+		var x uint64
+		switch v := tok.(type) {
+		case json.Number:
+			if opts.Hashed {
+				err = fmt.Errorf("expected a string key, got %s", v)
+			} else {
+				x, err = p.member(v.String())
+			}
+		case string:
+			if opts.Hashed {
+				x, err = p.member(v)
+			} else {
+				err = fmt.Errorf("expected an integer, got %q", v)
+			}
+		default:
+			err = fmt.Errorf("unexpected %v in the array of members", tok)
+		}
+		if err != nil {
+			return nil, &LineError{lineAt(dec.InputOffset()), err}
+		}
+		set.Add(x)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, &LineError{lineAt(dec.InputOffset()), err}
+	}
+	return set, nil
+}
+
+// Loads a member list from a file, by extension: .csv with ReadCSV, .json with ReadJSON and anything else with ReadLines.
+// Line errors are reported as path:line.
+func LoadFile(path string, opts LoadOptions) (*BitmapSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var set *BitmapSet
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		set, err = ReadCSV(f, opts)
+	case ".json":
+		set, err = ReadJSON(f, opts)
+	default:
+		set, err = ReadLines(f, opts)
+	}
+
+	var le *LineError
+	if errors.As(err, &le) {
+		return nil, fmt.Errorf("%s:%d: %w", path, le.Line, le.Err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return set, nil
+}
+
+// Writes the members of a source, one per line.
+func WriteLines(w io.Writer, src MembershipSource) error {
+	bw := bufio.NewWriter(w)
+	for x := range src.Members() {
+		bw.WriteString(strconv.FormatUint(x, 10))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// Writes the members of a source as a single CSV column under a header.
+func WriteCSV(w io.Writer, src MembershipSource, header string) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{header})
+	for x := range src.Members() {
+		cw.Write([]string{strconv.FormatUint(x, 10)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes the members of a source as a JSON array.
+func WriteJSON(w io.Writer, src MembershipSource) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+	first := true
+	for x := range src.Members() {
+		if !first {
+			bw.WriteByte(',')
+		}
+		first = false
+		bw.WriteString(strconv.FormatUint(x, 10))
+	}
+	bw.WriteString("]\n")
+	return bw.Flush()
+}
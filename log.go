@@ -0,0 +1,220 @@
+package zks
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"sync"
+
+	"github.com/smarky7cd/ZKS/verify"
+)
+
+// ErrLogRange is returned when asking a commitment log for an entry or size it doesn't have.
+var ErrLogRange = errors.New("zks: beyond the size of the commitment log")
+
+// An append-only log of the commitments published by a prover, stored on local disk.
+//
+// The log is a Merkle hash tree (RFC 9162) over the commitments. Clients comparing the root hashes they were shown
+// can check with inclusion proofs that a commitment is entry N of the log, and with consistency proofs that a later
+// log extends an earlier one, so a prover can't show different commitments to different clients.
+//
+// The file holds the 64-byte binary encodings of the entries one after the other.
+// A prover publishes to the log by committing under parameters holding it (see PubVerPar.WithLog).
+type CommitLog struct {
+	mu     sync.Mutex
+	file   *os.File
+	leaves [][]byte
+	last   Com
+}
+
+// Opens the commitment log stored at path, creating it if it doesn't exist.
+func OpenLog(path string) (*CommitLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if len(data)%64 != 0 {
+		file.Close()
+		return nil, fmt.Errorf("zks: commitment log %s is truncated", path)
+	}
+
+	l := &CommitLog{file: file}
+	for i := 0; i < len(data); i += 64 {
+		var c Com
+		if err := c.UnmarshalBinary(data[i : i+64]); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("zks: commitment log %s: entry %d: %w", path, i/64, err)
+		}
+		l.leaves = append(l.leaves, vectorLeaf(&c))
+		l.last = c
+	}
+	return l, nil
+}
+
+// Closes the file of the log.
+func (l *CommitLog) Close() error {
+	return l.file.Close()
+}
+
+// The number of entries in the log.
+func (l *CommitLog) Size() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return uint64(len(l.leaves))
+}
+
+// Appends the commitment to the log and syncs it to disk.
+// Returns the index of its entry. Publishing the latest entry again doesn't append it twice.
+func (l *CommitLog) Append(com Com) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.leaves) > 0 && l.last.Equals(com) {
+		return uint64(len(l.leaves) - 1), nil
+	}
+
+	data, _ := com.MarshalBinary()
+	if _, err := l.file.Write(data); err != nil {
+		return 0, err
+	}
+	if err := l.file.Sync(); err != nil {
+		return 0, err
+	}
+	l.leaves = append(l.leaves, vectorLeaf(&com))
+	l.last = com
+	return uint64(len(l.leaves) - 1), nil
+}
+
+// The parameters pp publishing every commitment computed under them to the log.
+//
+// Rep, RepWithOptions, LoadRepr, Repr.GrowUniverse and NewForest (its top-level commitment) append the commitment
+// they return before returning it and fail if they can't, as do the servers serving a commitment under the parameters.
+// The keys derived from the parameters (the sets of a forest) don't hold the log, so each commitment is appended once.
+func (pp *PubVerPar) WithLog(log *CommitLog) *PubVerPar {
+	logged := *pp
+	logged.log = log
+	return &logged
+}
+
+// Appends the commitment to the log of the parameters, if they hold one (see WithLog).
+// Publishing the latest entry of the log again doesn't append it twice.
+func (pp *PubVerPar) Publish(com Com) error {
+	if pp.log == nil {
+		return nil
+	}
+	_, err := pp.log.Append(com)
+	return err
+}
+
+// The largest power of two smaller than n, for n > 1.
+func splitPoint(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// Computes the Merkle tree hash of a list of leaves.
+func logRoot(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		h := sha256.Sum256(nil)
+		return h[:]
+	case 1:
+		return leaves[0]
+	}
+	k := splitPoint(len(leaves))
+	return verify.VectorNode(logRoot(leaves[:k]), logRoot(leaves[k:]))
+}
+
+// Computes the inclusion proof of leaf m in a list of leaves.
+func logPath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := splitPoint(len(leaves))
+	if m < k {
+		return append(logPath(m, leaves[:k]), logRoot(leaves[k:]))
+	}
+	return append(logPath(m-k, leaves[k:]), logRoot(leaves[:k]))
+}
+
+// Computes the consistency proof between the first m leaves and a list of leaves.
+// complete tells whether the first m leaves are the whole list the proof started from.
+func logSubproof(m int, leaves [][]byte, complete bool) [][]byte {
+	if m == len(leaves) {
+		if complete {
+			return nil
+		}
+		return [][]byte{logRoot(leaves)}
+	}
+	k := splitPoint(len(leaves))
+	if m <= k {
+		return append(logSubproof(m, leaves[:k], complete), logRoot(leaves[k:]))
+	}
+	return append(logSubproof(m-k, leaves[k:], false), logRoot(leaves[:k]))
+}
+
+// The root hash of the log when it had size entries.
+func (l *CommitLog) Root(size uint64) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if size > uint64(len(l.leaves)) {
+		return nil, ErrLogRange
+	}
+	return logRoot(l.leaves[:size]), nil
+}
+
+// The commitment of entry index.
+func (l *CommitLog) Entry(index uint64) (Com, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if index >= uint64(len(l.leaves)) {
+		return Com{}, ErrLogRange
+	}
+	data := make([]byte, 64)
+	if _, err := l.file.ReadAt(data, int64(index)*64); err != nil {
+		return Com{}, err
+	}
+	var c Com
+	err := c.UnmarshalBinary(data)
+	return c, err
+}
+
+// Computes the proof that entry index is included in the log when it had size entries.
+func (l *CommitLog) InclusionProof(index uint64, size uint64) ([][]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if index >= size || size > uint64(len(l.leaves)) {
+		return nil, ErrLogRange
+	}
+	return logPath(int(index), l.leaves[:size]), nil
+}
+
+// Computes the proof that the log when it had size entries extends the log when it had oldSize entries.
+func (l *CommitLog) ConsistencyProof(oldSize uint64, size uint64) ([][]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if oldSize > size || size > uint64(len(l.leaves)) {
+		return nil, ErrLogRange
+	}
+	if oldSize == 0 {
+		return nil, nil
+	}
+	return logSubproof(int(oldSize), l.leaves[:size], true), nil
+}
+
+// Verifies that the commitment com is entry index of the log of the given size with root hash root.
+func VerifyInclusion(com Com, index uint64, size uint64, proof [][]byte, root []byte) bool {
+	c := com.verifiable()
+	return verify.VerifyInclusion(&c, index, size, proof, root)
+}
+
+// Verifies that the log of the given size with root hash root extends the log of size oldSize with root hash oldRoot.
+func VerifyConsistency(oldSize uint64, size uint64, oldRoot []byte, root []byte, proof [][]byte) bool {
+	return verify.VerifyConsistency(oldSize, size, oldRoot, root, proof)
+}
@@ -0,0 +1,103 @@
+package zks
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/bwesterb/go-ristretto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	hmacprfpb "github.com/google/tink/go/proto/hmac_prf_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// Default domain string used to derive the commitment base h.
+const DefaultParamsDomain = "ZKS commitment base h v1"
+
+// Domain string used to derive the PRF key from a seed.
+const seedPRFDomain = "ZKS PRF key v1"
+
+// Derives the commitment base h from a public seed and domain string by hashing to the curve.
+//
+// Since h is the output of a hash function nobody knows log_g(h), so no one (including the prover)
+// holds a trapdoor with which hard commitments could be equivocated.
+func DeriveCommitmentBase(seed []byte, domain string) ristretto.Point {
+	bd := make([]byte, 8)
+	binary.BigEndian.PutUint64(bd, uint64(len(domain)))
+	buf := append(bd, []byte(domain)...)
+	buf = append(buf, seed...)
+
+	var h ristretto.Point
+	h.DeriveDalek(buf)
+	return h
+}
+
+// Generate h from a public seed and domain string and a fresh PRF.
+// The parameters can be checked by verifiers using VerifyParams.
+func GenVerifiable(seed []byte, domain string) *PubVerPar {
+	return newPubVerPar(DeriveCommitmentBase(seed, domain), newPRFKey())
+}
+
+// Recomputes h from the public seed and domain string.
+// Returns true if the public parameters use exactly this h, false otherwise.
+func VerifyParams(pp *PubVerPar, seed []byte, domain string) bool {
+	var zero ristretto.Point
+	zero.SetZero()
+	h := DeriveCommitmentBase(seed, domain)
+	return !pp.h.Equals(&zero) && pp.h.Equals(&h)
+}
+
+// Derives an HMAC-SHA256 PRF keyset from a seed.
+func derivePRFKey(seed []byte) *keyset.Handle {
+	bd := make([]byte, 8)
+	binary.BigEndian.PutUint64(bd, uint64(len(seedPRFDomain)))
+	kdf := sha256.New()
+	kdf.Write(bd)
+	kdf.Write([]byte(seedPRFDomain))
+	kdf.Write(seed)
+
+	key, _ := proto.Marshal(&hmacprfpb.HmacPrfKey{
+		Params:   &hmacprfpb.HmacPrfParams{Hash: commonpb.HashType_SHA256},
+		KeyValue: kdf.Sum(nil),
+	})
+	ks := &tinkpb.Keyset{
+		PrimaryKeyId: 1,
+		Key: []*tinkpb.Keyset_Key{{
+			KeyData: &tinkpb.KeyData{
+				TypeUrl:         "type.googleapis.com/google.crypto.tink.HmacPrfKey",
+				Value:           key,
+				KeyMaterialType: tinkpb.KeyData_SYMMETRIC,
+			},
+			Status:           tinkpb.KeyStatusType_ENABLED,
+			KeyId:            1,
+			OutputPrefixType: tinkpb.OutputPrefixType_RAW,
+		}},
+	}
+	kh, _ := insecurecleartextkeyset.Read(&keyset.MemReaderWriter{Keyset: ks})
+	return kh
+}
+
+// Generate h and the PRF key deterministically from a seed, for reproducible test vectors.
+// h is derived as by GenVerifiable under DefaultParamsDomain, the PRF key by hashing the seed under its own domain.
+//
+// Anyone holding the seed holds the prover key: parameters generated from a published seed hide nothing.
+func GenFromSeed(seed []byte) *PubVerPar {
+	return newPubVerPar(DeriveCommitmentBase(seed, DefaultParamsDomain), derivePRFKey(seed))
+}
+
+// Derives a 32-byte seed with the PRF of pp for a label and a name within a domain.
+// The label and name are hashed by the PRF, so derived seeds are independent of each other and of the PRF key of pp.
+func (pp *PubVerPar) deriveSeed(domain string, label string, name string) []byte {
+	msg := binary.AppendUvarint([]byte(domain), uint64(len(label)))
+	msg = append(msg, label...)
+	msg = append(msg, name...)
+	seed, _ := pp.ps.ComputePrimaryPRF(msg, 32)
+	return seed
+}
+
+// Derives the parameters with the PRF key seeded by deriveSeed.
+func (pp *PubVerPar) derive(domain string, label string, name string) *PubVerPar {
+	return newPubVerPar(pp.h, derivePRFKey(pp.deriveSeed(domain, label, name)))
+}
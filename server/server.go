@@ -0,0 +1,178 @@
+// Package server exposes a ZKS prover over HTTP.
+//
+// The routes are:
+//
+//	GET  /v1/params      the verifier params
+//	GET  /v1/commitment  the current commitment
+//	GET  /v1/query/{x}   the answer to a query on x
+//	POST /v1/query       the answers to a batch of queries
+//
+// Responses are JSON unless the request accepts application/octet-stream, in which case they use
+// the binary encodings of the zks package. A batch request body is a JSON wire.BatchRequest, or a binary one
+// with Content-Type application/octet-stream (see package wire).
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/smarky7cd/ZKS/wire"
+)
+
+// A Server answers queries on the ZKS it currently publishes.
+// The representation can be replaced at any time with Update; queries are answered concurrently.
+type Server struct {
+	pp   *zks.PubVerPar
+	mu   sync.RWMutex
+	repr *zks.Repr
+	com  zks.Com
+	mux  *http.ServeMux
+}
+
+// Creates a server publishing the commitment com to the representation repr under the prover parameters pp.
+// Returns an error if the commitment can't be appended to the log of pp (see zks.PubVerPar.WithLog).
+func New(pp *zks.PubVerPar, repr *zks.Repr, com zks.Com) (*Server, error) {
+	if err := pp.Publish(com); err != nil {
+		return nil, err
+	}
+	s := &Server{pp: pp, repr: repr, com: com, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /v1/params", s.handleParams)
+	s.mux.HandleFunc("GET /v1/commitment", s.handleCommitment)
+	s.mux.HandleFunc("GET /v1/query/{x}", s.handleQuery)
+	s.mux.HandleFunc("POST /v1/query", s.handleBatch)
+	return s, nil
+}
+
+// Publishes a new representation and its commitment.
+// The server keeps the current one if the commitment can't be appended to the log of its parameters.
+func (s *Server) Update(repr *zks.Repr, com zks.Com) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.pp.Publish(com); err != nil {
+		return err
+	}
+	s.repr, s.com = repr, com
+	return nil
+}
+
+// The representation and commitment currently published.
+func (s *Server) current() (*zks.Repr, zks.Com) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.repr, s.com
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Reports whether the client accepts binary responses.
+func wantsBinary(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), wire.BinaryType)
+}
+
+// Writes v as binary or JSON depending on what the client accepts.
+func write(w http.ResponseWriter, r *http.Request, v any) {
+	if m, ok := v.(encoding.BinaryMarshaler); ok && wantsBinary(r) {
+		data, err := m.MarshalBinary()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", wire.BinaryType)
+		w.Write(data)
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *Server) handleParams(w http.ResponseWriter, r *http.Request) {
+	write(w, r, s.pp)
+}
+
+func (s *Server) handleCommitment(w http.ResponseWriter, r *http.Request) {
+	_, com := s.current()
+	write(w, r, com)
+}
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	x, err := strconv.ParseUint(r.PathValue("x"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid element", http.StatusBadRequest)
+		return
+	}
+
+	repr, _ := s.current()
+	a := zks.Qry(s.pp, repr, x)
+	if a == nil {
+		http.Error(w, "element beyond the universe", http.StatusNotFound)
+		return
+	}
+	write(w, r, a)
+}
+
+// Reads the elements of a batch query.
+func readBatch(r *http.Request) ([]uint64, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 16*wire.MaxBatch+1024))
+	if err != nil {
+		return nil, err
+	}
+
+	if t, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); t != wire.BinaryType {
+		var req wire.BatchRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		return req.Xs, nil
+	}
+	return wire.ParseBatchRequest(body)
+}
+
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	xs, err := readBatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(xs) > wire.MaxBatch {
+		http.Error(w, "batch too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// answer the whole batch from the same representation
+	repr, _ := s.current()
+	answers := make([]*zks.Answer, len(xs))
+	for i, x := range xs {
+		if answers[i] = zks.Qry(s.pp, repr, x); answers[i] == nil {
+			http.Error(w, "element beyond the universe", http.StatusNotFound)
+			return
+		}
+	}
+
+	if !wantsBinary(r) {
+		write(w, r, &wire.BatchResponse{Answers: answers})
+		return
+	}
+
+	buf, err := wire.AppendBatchResponse(nil, answers)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", wire.BinaryType)
+	w.Write(buf)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/smarky7cd/ZKS/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBadRequests(t *testing.T) {
+	pp := zks.Gen()
+	repr, com := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{1: true}, 8))
+	srv, err := New(pp, repr, com)
+	assert.Nil(t, err)
+
+	cases := []struct {
+		method, path, contentType, body string
+		status                          int
+	}{
+		{"GET", "/v1/query/abc", "", "", http.StatusBadRequest},
+		{"GET", "/v1/query/8", "", "", http.StatusNotFound},
+		{"POST", "/v1/query", "application/json", "{", http.StatusBadRequest},
+		{"POST", "/v1/query", wire.BinaryType, "\x02\x00", http.StatusBadRequest},
+		{"POST", "/v1/query", "application/json", `{"xs":[1,9]}`, http.StatusNotFound},
+		{"DELETE", "/v1/commitment", "", "", http.StatusMethodNotAllowed},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		req.Header.Set("Content-Type", c.contentType)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		assert.Equal(t, c.status, w.Code, "%s %s %q", c.method, c.path, c.body)
+	}
+
+	// the params never include the PRF key
+	req := httptest.NewRequest("GET", "/v1/params", nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "prf")
+}
+
+func TestConcurrentQueries(t *testing.T) {
+	pp := zks.Gen()
+	repr, com := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{1: true, 6: true}, 64))
+	srv, err := New(pp, repr, com)
+	assert.Nil(t, err)
+
+	done := make(chan int)
+	for g := 0; g < 8; g++ {
+		go func() {
+			codes := 0
+			for x := 0; x < 16; x++ {
+				w := httptest.NewRecorder()
+				srv.ServeHTTP(w, httptest.NewRequest("GET", "/v1/query/"+strconv.Itoa(x*4+g%4), nil))
+				if w.Code == http.StatusOK {
+					codes++
+				}
+			}
+			done <- codes
+		}()
+	}
+	go srv.Update(zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{2: true}, 64)))
+
+	for g := 0; g < 8; g++ {
+		assert.Equal(t, 16, <-done)
+	}
+}
+
+func TestPublishesToLog(t *testing.T) {
+	log, err := zks.OpenLog(filepath.Join(t.TempDir(), "coms.log"))
+	assert.Nil(t, err)
+	pp := zks.Gen()
+	logged := pp.WithLog(log)
+
+	// serving the commitment it was computed under the log with doesn't append it twice
+	repr, com := zks.Rep(logged, zks.NewEnumSet(map[uint64]bool{1: true}, 8))
+	srv, err := New(logged, repr, com)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), log.Size())
+
+	// updates are appended, even of commitments computed without the log
+	repr2, com2 := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{2: true}, 8))
+	assert.Nil(t, srv.Update(repr2, com2))
+	assert.Nil(t, srv.Update(repr2, com2))
+	assert.Equal(t, uint64(2), log.Size())
+	latest, _ := log.Entry(1)
+	assert.True(t, latest.Equals(com2))
+
+	// an update that can't be logged isn't served
+	assert.Nil(t, log.Close())
+	assert.NotNil(t, srv.Update(repr, com))
+	_, current := srv.current()
+	assert.True(t, current.Equals(com2))
+}
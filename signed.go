@@ -0,0 +1,77 @@
+package zks
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// SchemeID identifies the commitment and signature schemes of a signed commitment.
+const SchemeID = "zks-ristretto255-ed25519-v1"
+
+// ErrUnknownScheme is returned when verifying a signed commitment of another scheme.
+var ErrUnknownScheme = errors.New("zks: unknown signed commitment scheme")
+
+// ErrBadSignature is returned when the signature of a signed commitment does not verify.
+var ErrBadSignature = errors.New("zks: invalid signature on commitment")
+
+// A commitment signed by the prover (a signed tree head).
+// It binds the root to the depth and arity of the tree, the epoch it was published in and the time it was signed at,
+// so clients holding the public key of the prover know who published a commitment and when.
+type SignedCommitment struct {
+	Com       Com       `json:"commitment"`
+	Depth     uint64    `json:"depth"`
+	Arity     uint64    `json:"arity"`
+	Epoch     uint64    `json:"epoch"`
+	Timestamp time.Time `json:"timestamp"`
+	Scheme    string    `json:"scheme"`
+	Signature []byte    `json:"signature"`
+}
+
+// Encodes the signed fields of the commitment.
+func (sc *SignedCommitment) message() []byte {
+	buf := binary.BigEndian.AppendUint64(nil, uint64(len(sc.Scheme)))
+	buf = append(buf, sc.Scheme...)
+	buf = append(buf, sc.Com.c0.Bytes()...)
+	buf = append(buf, sc.Com.c1.Bytes()...)
+	buf = binary.BigEndian.AppendUint64(buf, sc.Depth)
+	buf = binary.BigEndian.AppendUint64(buf, sc.Arity)
+	buf = binary.BigEndian.AppendUint64(buf, sc.Epoch)
+	return binary.BigEndian.AppendUint64(buf, uint64(sc.Timestamp.UnixNano()))
+}
+
+// Input: the private key of the prover, a ZKS representation, an epoch number and a time.
+// Return: the commitment to the representation signed with Ed25519.
+func Sign(priv ed25519.PrivateKey, repr *Repr, epoch uint64, ts time.Time) *SignedCommitment {
+	com := Com{repr.tree.root.c0, repr.tree.root.c1}
+	sc := &SignedCommitment{com, repr.tree.levels, repr.tree.arity, epoch, ts.UTC().Round(0), SchemeID, nil}
+	sc.Signature = ed25519.Sign(priv, sc.message())
+	return sc
+}
+
+// Verifies the signature of the prover on a signed commitment.
+// Returns nil if the commitment was signed with the private key of pub, an error otherwise.
+func VerifySigned(pub ed25519.PublicKey, sc *SignedCommitment) error {
+	if sc.Scheme != SchemeID {
+		return ErrUnknownScheme
+	}
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, sc.message(), sc.Signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// Reports whether the answer is a path in a tree of the signed depth and arity.
+func (sc *SignedCommitment) shapes(answer *Answer) bool {
+	return answer != nil && answer.levels == sc.Depth && answer.arity == sc.Arity
+}
+
+// Input: The public parameters (h,ps), the public key of the prover, a signed commitment, an element x that was queried, and the answer/proof struct.
+// Return: True if the commitment is signed by the prover and the answer verifies under it, false otherwise.
+func VfySigned(pp *PubVerPar, pub ed25519.PublicKey, sc *SignedCommitment, x uint64, answer *Answer) bool {
+	if VerifySigned(pub, sc) != nil || !sc.shapes(answer) {
+		return false
+	}
+	return Vfy(pp, sc.Com, x, answer)
+}
@@ -0,0 +1,97 @@
+package zks
+
+import (
+	"github.com/bwesterb/go-ristretto"
+	mc "github.com/smarky7CD/go-dl-mercurial-commitments"
+)
+
+// A zero-knowledge simulator for the ZKS.
+//
+// The simulator knows the trapdoor t = log_g(h) of its public parameters. Every node of its tree is a
+// fake commitment (a*g, b*g) which, using t, can be hard-opened as well as teased to any message.
+// It therefore commits to no set at all and answers queries given only the membership bit,
+// producing answers that verify exactly like those of an honest prover.
+//
+// Since answers can be made up after the fact, simulated fixtures are deniable:
+// they prove nothing to anyone who does not trust that h was generated honestly.
+type Simulator struct {
+	pp       *PubVerPar
+	trapdoor ristretto.Scalar
+	tree     Tree
+}
+
+// Creates a simulator for a universe of max values with the given tree options.
+// Its public parameters hold a trapdoored h and its commitment is independent of any set.
+func NewSimulator(max uint64, opts TreeOptions) (*Simulator, error) {
+	levels, arity, err := treeShape(max, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var t ristretto.Scalar
+	var h ristretto.Point
+	t.Rand()
+	h.ScalarMultBase(&t)
+	pp := newPubVerPar(h, newPRFKey())
+
+	var tree = make(map[uint64]map[uint64]*TreeNode)
+	for j := uint64(0); j <= levels; j++ {
+		tree[j] = make(map[uint64]*TreeNode)
+	}
+	tree[0][0] = softNode(pp, 0, 0)
+
+	return &Simulator{pp, t, Tree{*tree[0][0], tree, levels, arity, nil}}, nil
+}
+
+// The trapdoored public parameters of the simulator.
+func (sim *Simulator) Params() *PubVerPar {
+	return sim.pp
+}
+
+// The commitment of the simulator.
+func (sim *Simulator) Com() Com {
+	return Com{sim.tree.root.c0, sim.tree.root.c1}
+}
+
+// Opens the fake commitment of a node to msg as if it were a hard commitment.
+// With c1 = b*g = (b/t)*h and c0 = a*g = msg*g + ((a-msg)/b)*c1.
+func (sim *Simulator) equivocate(node *TreeNode, msg []byte) *Open {
+	var tinv, pi1 ristretto.Scalar
+	tinv.Inverse(&sim.trapdoor)
+	pi1.Mul(&node.r1, &tinv)
+	return &Open{mc.SoftTease(msg, &node.r0, &node.r1), pi1}
+}
+
+// Input: an element x and the membership bit the answer should claim.
+// Return: Answer struct claiming membership (or non-membership) of x that verifies under the simulator's commitment.
+// The fake commitments along the path are derived for their position, as the missing nodes of NonMemberPath are,
+// so they are the same for every query and the simulator answers queries concurrently.
+func (sim *Simulator) Qry(x uint64, member bool) *Answer {
+	if !inCapacity(x, sim.tree.levels, sim.tree.arity) {
+		return nil
+	}
+	tree := sim.tree.pathCopy(sim.pp, x)
+
+	answer := tree.pathAnswer(member, x)
+	for j := uint64(0); j <= tree.levels; j++ {
+		xi := tree.index(x, j)
+		val := tree.tree[j][xi]
+
+		var msg []byte
+		if j < tree.levels {
+			msg = tree.message(xi, j)
+		} else if member {
+			msg = nodeID(x, j)
+		} else {
+			msg = []byte("bot")
+		}
+
+		if member {
+			answer.opens[j] = sim.equivocate(val, msg)
+		} else {
+			r := mc.SoftTease(msg, &val.r0, &val.r1)
+			answer.teases[j] = &r
+		}
+	}
+	return answer
+}
@@ -0,0 +1,162 @@
+package zks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsortedMembers is returned when a membership source yields members out of ascending order.
+var ErrUnsortedMembers = errors.New("zks: members are not in strictly ascending order")
+
+// A source of the set a ZKS commits to.
+//
+// Max is the universe bound: members are below it, or any uint64 for the bound math.MaxUint64 (see inUniverse).
+// In reports whether x is a member.
+// Members yields the members in strictly ascending order. Rep builds the tree in a single pass over Members,
+// so the source need not hold the set in memory, although the tree holds the path to every member.
+type MembershipSource interface {
+	Max() uint64
+	In(x uint64) bool
+	Members() iter.Seq[uint64]
+}
+
+// Reports whether x is in the universe of the bound max.
+// The bound of a universe of every uint64, 2^64, doesn't fit a uint64, so math.MaxUint64 stands for it.
+func inUniverse(x uint64, max uint64) bool {
+	return x < max || max == math.MaxUint64
+}
+
+// Sources whose iteration can fail (e.g. reading a file) iterate their members with Scan,
+// which also returns the error of that iteration, to be read once it is done.
+type failingSource interface {
+	Scan() (iter.Seq[uint64], func() error)
+}
+
+// The members of a source and the error of their iteration (see failingSource), nil for sources that can't fail.
+func scanMembers(src MembershipSource) (iter.Seq[uint64], func() error) {
+	if fs, ok := src.(failingSource); ok {
+		return fs.Scan()
+	}
+	return src.Members(), func() error { return nil }
+}
+
+// A set given by its sorted members.
+// It takes 8 bytes per member, and membership is tested by binary search.
+type SortedSet struct {
+	xs  []uint64
+	max uint64
+}
+
+// Creates a set from members sorted in strictly ascending order, all below max.
+// The slice is used as is and must not be modified afterwards.
+func NewSortedSet(xs []uint64, max uint64) (*SortedSet, error) {
+	for i, x := range xs {
+		if i > 0 && x <= xs[i-1] {
+			return nil, ErrUnsortedMembers
+		}
+		if !inUniverse(x, max) {
+			return nil, fmt.Errorf("zks: member %d is not below the universe bound %d", x, max)
+		}
+	}
+	return &SortedSet{xs, max}, nil
+}
+
+// Reads members from ch until it is closed, in any order, and collects them into a sorted set.
+// This is not a streaming source: the whole channel is held in memory and sorted before the set is returned.
+// Use a FileSet or a MembershipSource of your own to stream members in ascending order.
+func ReadChannel(ch <-chan uint64, max uint64) (*SortedSet, error) {
+	var xs []uint64
+	for x := range ch {
+		xs = append(xs, x)
+	}
+	slices.Sort(xs)
+	return NewSortedSet(slices.Compact(xs), max)
+}
+
+func (s *SortedSet) Max() uint64 {
+	return s.max
+}
+
+func (s *SortedSet) In(x uint64) bool {
+	_, ok := slices.BinarySearch(s.xs, x)
+	return ok
+}
+
+func (s *SortedSet) Members() iter.Seq[uint64] {
+	return slices.Values(s.xs)
+}
+
+// A set read from a file of members, one integer per line in strictly ascending order.
+// Blank lines and lines starting with # are ignored.
+//
+// The file is streamed every time the members are iterated, so the source never holds the set in memory.
+// Membership is tested by scanning the file and is slow: the set is meant to be passed to Rep.
+// Every iteration has an error of its own (see Scan), so the set can be iterated concurrently.
+type FileSet struct {
+	path string
+	max  uint64
+}
+
+// Creates a set reading its members from the file at path.
+func NewFileSet(path string, max uint64) *FileSet {
+	return &FileSet{path: path, max: max}
+}
+
+func (f *FileSet) Max() uint64 {
+	return f.max
+}
+
+func (f *FileSet) In(x uint64) bool {
+	for m := range f.Members() {
+		if m >= x {
+			return m == x
+		}
+	}
+	return false
+}
+
+// Yields the members of the file. An iteration that fails ends early; use Scan to learn why.
+func (f *FileSet) Members() iter.Seq[uint64] {
+	members, _ := f.Scan()
+	return members
+}
+
+// Returns the members of the file and a function reporting the error that stopped their iteration, if any,
+// once it is done. The error belongs to this iteration only.
+func (f *FileSet) Scan() (iter.Seq[uint64], func() error) {
+	var scanErr error
+	members := func(yield func(uint64) bool) {
+		scanErr = nil
+		file, err := os.Open(f.path)
+		if err != nil {
+			scanErr = err
+			return
+		}
+		defer file.Close()
+
+		sc := bufio.NewScanner(file)
+		for n := 1; sc.Scan(); n++ {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			x, err := strconv.ParseUint(line, 10, 64)
+			if err != nil {
+				scanErr = fmt.Errorf("%s:%d: %w", f.path, n, err)
+				return
+			}
+			if !yield(x) {
+				return
+			}
+		}
+		scanErr = sc.Err()
+	}
+	return members, func() error { return scanErr }
+}
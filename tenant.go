@@ -0,0 +1,198 @@
+package zks
+
+import (
+	"crypto/rand"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/bwesterb/go-ristretto"
+	mc "github.com/smarky7CD/go-dl-mercurial-commitments"
+)
+
+// ErrUnknownTenant is returned when querying a tenant the registry holds no set for.
+var ErrUnknownTenant = errors.New("zks: unknown tenant")
+
+// Domain string of the keys derived from a master key.
+const masterKeyDomain = "ZKS master key v1"
+
+// The size of the secret of a master key.
+const masterSecretSize = 32
+
+// A master key derives the prover keys of many tenants.
+//
+// h is the commitment base shared by every tenant, so all tenants have the same verifier params.
+// secret seeds a PRF key from which the PRF key (Params) and the master keys of children (Child) are derived
+// under the tenant ID, as the keys of the sets of a forest are. Derived keys are independent: knowing the keys of one tenant
+// reveals nothing about the keys of its parent or siblings, so one tenant's proofs reveal nothing about another's set.
+//
+// The master key holds the secret in cleartext and must be kept like a prover key.
+// A master key with a log (see WithLog) hands it to its children and parameters, so every tenant publishes to it.
+type MasterKey struct {
+	h      ristretto.Point
+	secret []byte
+	log    *CommitLog
+}
+
+// Generate a master key with a fresh h and secret.
+func GenMaster() *MasterKey {
+	secret := make([]byte, masterSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return &MasterKey{h: mc.GeneratePublicParameters(), secret: secret}
+}
+
+// The parameters with the PRF key seeded by the secret, from which all keys of the master key are derived.
+func (mk *MasterKey) prf() *PubVerPar {
+	return newPubVerPar(mk.h, derivePRFKey(mk.secret))
+}
+
+// The master key of the tenant with the given ID, which derives the keys of its own children in turn.
+func (mk *MasterKey) Child(id string) *MasterKey {
+	return &MasterKey{mk.h, mk.prf().deriveSeed(masterKeyDomain, "child", id), mk.log}
+}
+
+// The prover parameters (h and the derived PRF key) of the master key, publishing to its log if it holds one.
+func (mk *MasterKey) Params() *PubVerPar {
+	return mk.prf().derive(masterKeyDomain, "prf", "").WithLog(mk.log)
+}
+
+// The master key mk publishing the commitments of its own and its children's parameters to the log.
+func (mk *MasterKey) WithLog(log *CommitLog) *MasterKey {
+	logged := *mk
+	logged.log = log
+	return &logged
+}
+
+// The verifier parameters (h) shared by the master key and all its children.
+func (mk *MasterKey) VerifierParams() *PubVerPar {
+	return &PubVerPar{h: mk.h}
+}
+
+// A registry of tenants serves the sets of many tenants from one master key.
+// Each tenant has the prover parameters of its child of the master key. It is safe for concurrent use.
+type Registry struct {
+	master  *MasterKey
+	mu      sync.RWMutex
+	tenants map[string]*tenant
+}
+
+// A tenant of a registry: its prover parameters, representation and commitment.
+// publishing serialises the publishes of the tenant, so its sets are committed (and logged) in the order they are stored.
+// A tenant whose first set is being published has no representation yet.
+type tenant struct {
+	pp         *PubVerPar
+	publishing sync.Mutex
+	repr       *Repr
+	com        Com
+}
+
+// Creates an empty registry deriving the keys of its tenants from the master key.
+func NewRegistry(master *MasterKey) *Registry {
+	return &Registry{master: master, tenants: make(map[string]*tenant)}
+}
+
+// Input: a tenant ID, the membership source of its set and the options shaping the tree.
+// Return: the commitment to the set of the tenant, or an error if the source or options are invalid.
+// The set replaces any previous set of the tenant. The representation is deterministic in the master key and the set.
+// Publishes of the same tenant take turns, those of different tenants run concurrently.
+func (r *Registry) Publish(id string, src MembershipSource, opts TreeOptions) (Com, error) {
+	t := r.lockTenant(id)
+	defer t.publishing.Unlock()
+
+	repr, com, err := RepWithOptions(t.pp, src, opts)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		if t.repr == nil {
+			delete(r.tenants, id)
+		}
+		return Com{}, err
+	}
+	t.repr, t.com = repr, com
+	return com, nil
+}
+
+// The tenant with the given ID, registered without a set if the registry holds none, locked for publishing.
+func (r *Registry) lockTenant(id string) *tenant {
+	for {
+		r.mu.Lock()
+		t, ok := r.tenants[id]
+		if !ok {
+			t = &tenant{pp: r.master.Child(id).Params()}
+			r.tenants[id] = t
+		}
+		r.mu.Unlock()
+
+		t.publishing.Lock()
+		r.mu.RLock()
+		registered := r.tenants[id] == t
+		r.mu.RUnlock()
+		if registered {
+			return t
+		}
+		// the tenant was removed while waiting for its turn
+		t.publishing.Unlock()
+	}
+}
+
+// Removes the set of a tenant, once a publish of the tenant in progress is done.
+func (r *Registry) Remove(id string) {
+	r.mu.RLock()
+	t, ok := r.tenants[id]
+	r.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	t.publishing.Lock()
+	defer t.publishing.Unlock()
+	r.mu.Lock()
+	if r.tenants[id] == t {
+		delete(r.tenants, id)
+	}
+	r.mu.Unlock()
+}
+
+// The IDs of the tenants holding a set in ascending order.
+func (r *Registry) Tenants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.tenants))
+	for id, t := range r.tenants {
+		if t.repr != nil {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// The commitment to the set of a tenant, false if the registry holds no set for it.
+func (r *Registry) Commitment(id string) (Com, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tenants[id]
+	if !ok || t.repr == nil {
+		return Com{}, false
+	}
+	return t.com, true
+}
+
+// Input: a tenant ID and an element x.
+// Return: Answer for x in the set of the tenant (nil beyond the leaves of its tree, see Qry), or ErrUnknownTenant.
+// Answers verify with Vfy under the verifier params of the master key and the commitment of the tenant.
+func (r *Registry) Qry(id string, x uint64) (*Answer, error) {
+	r.mu.RLock()
+	t, ok := r.tenants[id]
+	var repr *Repr
+	if ok {
+		repr = t.repr
+	}
+	r.mu.RUnlock()
+	if repr == nil {
+		return nil, ErrUnknownTenant
+	}
+	return Qry(t.pp, repr, x), nil
+}
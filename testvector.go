@@ -0,0 +1,81 @@
+package zks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+//go:generate go run ./cmd/zks-vectors -o testdata/vectors.json
+
+// A known-answer test vector: a set committed to under parameters generated from a seed with GenFromSeed,
+// and the answers to queries on chosen elements. Everything in it is deterministic, so any implementation
+// can regenerate the vector byte-for-byte.
+//
+// Repr is the snapshot of the representation (see Repr.MarshalJSON).
+type TestVector struct {
+	Seed    []byte          `json:"seed"`
+	Repr    json.RawMessage `json:"repr"`
+	Params  *PubVerPar      `json:"params"`
+	Com     Com             `json:"commitment"`
+	Answers []TestAnswer    `json:"answers"`
+}
+
+// The answer to a query on X in a test vector.
+type TestAnswer struct {
+	X      uint64  `json:"x"`
+	Answer *Answer `json:"answer"`
+}
+
+// Input: a seed, a membership source, the options shaping the tree and the elements to query.
+// Return: the test vector holding the commitment to the set and the answers on xs, or an error if the options are invalid.
+func GenTestVector(seed []byte, src MembershipSource, opts TreeOptions, xs []uint64) (*TestVector, error) {
+	pp := GenFromSeed(seed)
+	repr, com, err := RepWithOptions(pp, src, opts)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := repr.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &TestVector{seed, snapshot, pp, com, []TestAnswer{}}
+	for _, x := range xs {
+		answer := Qry(pp, repr, x)
+		if answer == nil {
+			return nil, fmt.Errorf("zks: element %d is beyond the universe", x)
+		}
+		v.Answers = append(v.Answers, TestAnswer{x, answer})
+	}
+	return v, nil
+}
+
+// Regenerates the test vector from its seed and set and compares the binary encodings of the parameters,
+// the commitment and every answer, which must also verify.
+// Returns nil if the vector is reproduced exactly, an error describing the first difference otherwise.
+func (v *TestVector) Check() error {
+	pp := GenFromSeed(v.Seed)
+	repr, com, err := LoadRepr(pp, v.Repr)
+	if err != nil {
+		return err
+	}
+
+	if v.Params == nil || !v.Params.h.Equals(&pp.h) {
+		return fmt.Errorf("zks: test vector params differ")
+	}
+	if !v.Com.Equals(com) {
+		return fmt.Errorf("zks: test vector commitment differs")
+	}
+	for _, ta := range v.Answers {
+		if !Vfy(v.Params, v.Com, ta.X, ta.Answer) {
+			return fmt.Errorf("zks: test vector answer for %d does not verify", ta.X)
+		}
+		want, _ := Qry(pp, repr, ta.X).MarshalBinary()
+		got, _ := ta.Answer.MarshalBinary()
+		if !bytes.Equal(want, got) {
+			return fmt.Errorf("zks: test vector answer for %d differs", ta.X)
+		}
+	}
+	return nil
+}
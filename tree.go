@@ -1,13 +1,22 @@
 package zks
 
 import (
-	"encoding/binary"
-	"math"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
 
 	"github.com/bwesterb/go-ristretto"
 	mc "github.com/smarky7CD/go-dl-mercurial-commitments"
+	"github.com/smarky7cd/ZKS/verify"
 )
 
+// ErrInvalidArity is returned when a tree arity is not a power of two greater than one.
+var ErrInvalidArity = errors.New("zks: arity must be a power of two greater than one")
+
+// ErrDepthTooSmall is returned when a fixed tree depth cannot hold the universe.
+var ErrDepthTooSmall = errors.New("zks: fixed depth is too small for the universe")
+
 // soft indicates whether the nodes is a hard or soft commitment.
 // c0,c1 is the commitment to the node.
 // r0,r1 random scalars used to (could instead be computed on the fly).
@@ -24,151 +33,343 @@ func NewNode(soft bool, c0 ristretto.Point, c1 ristretto.Point, r0 ristretto.Sca
 	return &TreeNode{soft, c0, c1, r0, r1}
 }
 
+// The commitment held by the node.
+func (node *TreeNode) com() *Com {
+	return &Com{node.c0, node.c1}
+}
+
 // Tree is the internal ZKS representation.
 // root is the commitment to the ZKS.
 // tree is a nested map of nodes -- one map per level.
 // levels is the depth of the tree.
+// arity is the number of children of every internal node.
+// grown lists the depths the tree had before it was grown (see Repr.GrowUniverse), in ascending order.
 type Tree struct {
 	root   TreeNode
 	tree   map[uint64]map[uint64]*TreeNode
 	levels uint64
+	arity  uint64
+	grown  []uint64
+}
+
+// Options controlling the shape of a tree.
+//
+// Arity is the number of children of every internal node and must be a power of two.
+// A binary tree (the zero value) commits to both children of a node directly, wider trees
+// commit to their children through a vector commitment so proofs have log_q(U) levels.
+//
+// Depth fixes the number of levels of the tree regardless of the universe size (e.g. 64 levels of a
+// binary tree hold every uint64), so answers reveal nothing about the universe. Zero derives the depth
+// from the universe size. Levels above the members are never materialised, only the path to them is.
+type TreeOptions struct {
+	Arity uint64
+	Depth uint64
+}
+
+// Reports whether arity is a power of two greater than one.
+func ValidArity(arity uint64) bool {
+	return verify.ValidArity(arity)
 }
 
-// Computes the next highest power of 2 on input n.
+// Computes the next highest power of 2 on input n, i.e. the number of bits needed to index n values.
+// Returns 0 for n <= 1: empty and single-element universes are held by a tree consisting of the root only.
 func ComputeNearestPowerof2(n uint64) uint64 {
-	return uint64(math.Ceil(math.Log2(float64(n))))
+	if n <= 1 {
+		return 0
+	}
+	return uint64(bits.Len64(n - 1))
+}
+
+// Computes the depth of a tree with the given arity over a universe of n values.
+func ComputeDepth(n uint64, arity uint64) uint64 {
+	b := arityBits(arity)
+	return (ComputeNearestPowerof2(n) + b - 1) / b
+}
+
+// The number of bits of an element consumed by each level of a tree with the given arity.
+func arityBits(arity uint64) uint64 {
+	return verify.ArityBits(arity)
+}
+
+// Computes the index of the node on the path to x at a level of a tree with the given depth and arity.
+func pathIndex(x uint64, levels uint64, arity uint64, level uint64) uint64 {
+	return verify.PathIndex(x, levels, arity, level)
+}
+
+// Reports whether x is one of the leaves of a tree with the given depth and arity.
+// Elements beyond the leaves (at least arity^levels) have no path to the root and can't be proven.
+func inCapacity(x uint64, levels uint64, arity uint64) bool {
+	return verify.InCapacity(x, levels, arity)
+}
+
+// Encodes a node index and its level. Used as PRF input and as the message of member leaves.
+func nodeID(x uint64, level uint64) []byte {
+	return verify.NodeID(x, level)
+}
+
+// Derives the random scalars of node x at a level from the PRF.
+func deriveRandomness(pp *PubVerPar, x uint64, level uint64) (ristretto.Scalar, ristretto.Scalar) {
+	return deriveRandomnessIn(pp, nil, x, level)
+}
+
+// Derives the random scalars of node x at a level from the PRF, its input prefixed by a domain.
+func deriveRandomnessIn(pp *PubVerPar, domain []byte, x uint64, level uint64) (ristretto.Scalar, ristretto.Scalar) {
+	ra0, _ := pp.ps.ComputePrimaryPRF(append(slices.Clip(domain), nodeID(x, level)...), 32)
+	ra1, _ := pp.ps.ComputePrimaryPRF(ra0, 32)
+	var r0, r1 ristretto.Scalar
+	r0.Derive(ra0)
+	r1.Derive(ra1)
+	return r0, r1
+}
+
+// Computes a hard commitment to msg for node x at a level.
+func hardNode(pp *PubVerPar, x uint64, level uint64, msg []byte) *TreeNode {
+	r0, r1 := deriveRandomness(pp, x, level)
+	c0, c1 := mc.HardCommit(&pp.h, msg, &r0, &r1)
+	return NewNode(false, c0, c1, r0, r1)
+}
+
+// Computes a soft commitment for node x at a level.
+func softNode(pp *PubVerPar, x uint64, level uint64) *TreeNode {
+	r0, r1 := deriveRandomness(pp, x, level)
+	c0, c1 := mc.SoftCommit(&r0, &r1)
+	return NewNode(true, c0, c1, r0, r1)
+}
+
+// Computes the message an internal node commits to given the commitments of its children.
+// Binary nodes commit to the concatenation of both children, wider nodes to the vector commitment of all children.
+func childrenMessage(arity uint64, coms []*Com) []byte {
+	if arity == 2 {
+		l, r := coms[0].verifiable(), coms[1].verifiable()
+		return verify.PairMessage(&l, &r)
+	}
+	return VectorCommit(coms)
 }
 
-// Computes the leaves of the tree.
-func ComputeLeaves(pp *PubVerPar, es *EnumSet, level uint64) map[uint64]*TreeNode {
+// Collects the commitments of the group of siblings node i belongs to in a layer.
+func groupComs(layer map[uint64]*TreeNode, i uint64, arity uint64) []*Com {
+	base := i &^ (arity - 1)
+	coms := make([]*Com, arity)
+	for k := uint64(0); k < arity; k++ {
+		coms[k] = layer[base+k].com()
+	}
+	return coms
+}
+
+// Computes the leaves of the tree in a single pass over the members of the source.
+// Only the groups of siblings containing a member are materialised.
+// Returns an error if the members are not in ascending order, not below the universe bound or can't be read.
+func ComputeLeaves(pp *PubVerPar, src MembershipSource, level uint64, arity uint64) (map[uint64]*TreeNode, error) {
+	return computeLeaves(pp, src, level, arity, nodeID)
+}
+
+// Computes the leaves of the tree, the leaf of member x committing to leaf(x, level).
+func computeLeaves(pp *PubVerPar, src MembershipSource, level uint64, arity uint64, leaf func(uint64, uint64) []byte) (map[uint64]*TreeNode, error) {
 	var leaves = make(map[uint64]*TreeNode)
 
-	for x := uint64(0); x < uint64(math.Pow(2, float64(level))); x++ {
-
-		if es.In(x) {
-			bx := make([]byte, 8)
-			bl := make([]byte, 8)
-			binary.PutUvarint(bx, x)
-			binary.PutUvarint(bl, level)
-			bx = append(bx, bl...)
-			ra0, _ := pp.ps.ComputePrimaryPRF(bx, 32)
-			ra1, _ := pp.ps.ComputePrimaryPRF(ra0, 32)
-			var r0, r1 ristretto.Scalar
-			r0.Derive(ra0)
-			r1.Derive(ra1)
-			c0, c1 := mc.HardCommit(&pp.h, bx, &r0, &r1)
-			leaves[x] = NewNode(false, c0, c1, r0, r1)
+	// materialises the group of siblings of the given members
+	materialise := func(group []uint64) {
+		base := group[0] &^ (arity - 1)
+		for k := uint64(0); k < arity; k++ {
+			x := base + k
+			if len(group) > 0 && group[0] == x {
+				leaves[x] = hardNode(pp, x, level, leaf(x, level))
+				group = group[1:]
+			} else {
+				leaves[x] = softNode(pp, x, level)
+			}
+		}
+	}
+
+	max := src.Max()
+	members, scanErr := scanMembers(src)
+	var group []uint64
+	var prev uint64
+	first := true
+	for m := range members {
+		if !first && m <= prev {
+			return nil, ErrUnsortedMembers
+		}
+		first, prev = false, m
+		if !inUniverse(m, max) {
+			return nil, fmt.Errorf("zks: member %d is not below the universe bound %d", m, max)
+		}
+
+		// a tree of depth 0 is a single leaf without siblings
+		if level == 0 {
+			leaves[m] = hardNode(pp, m, level, leaf(m, level))
+			continue
 		}
 
-		if !es.In(x) && es.In(x^1) {
-			bx := make([]byte, 8)
-			bl := make([]byte, 8)
-			binary.PutUvarint(bx, x)
-			binary.PutUvarint(bl, level)
-			bx = append(bx, bl...)
-			ra0, _ := pp.ps.ComputePrimaryPRF(bx, 32)
-			ra1, _ := pp.ps.ComputePrimaryPRF(ra0, 32)
-			var r0, r1 ristretto.Scalar
-			r0.Derive(ra0)
-			r1.Derive(ra1)
-			c0, c1 := mc.SoftCommit(&r0, &r1)
-			leaves[x] = NewNode(true, c0, c1, r0, r1)
+		if len(group) > 0 && m&^(arity-1) != group[0]&^(arity-1) {
+			materialise(group)
+			group = group[:0]
 		}
+		group = append(group, m)
+	}
+	if len(group) > 0 {
+		materialise(group)
 	}
-	return leaves
+
+	if err := scanErr(); err != nil {
+		return nil, err
+	}
+	return leaves, nil
 }
 
 // Computes the non-leaf layers of the tree representation.
-func ComputeLayer(pp *PubVerPar, level uint64, prev_layer_nodes map[uint64]*TreeNode) map[uint64]*TreeNode {
+// Parents of the previous layer are hard commitments to their children, their missing siblings soft commitments.
+func ComputeLayer(pp *PubVerPar, level uint64, prev_layer_nodes map[uint64]*TreeNode, arity uint64) map[uint64]*TreeNode {
 	var layer_nodes = make(map[uint64]*TreeNode)
 
-	for i := uint64(0); i < uint64(math.Pow(2, (float64(level)))); i++ {
+	for i := range prev_layer_nodes {
+		if i%arity == 0 {
+			bsigma := childrenMessage(arity, groupComs(prev_layer_nodes, i, arity))
+			layer_nodes[i/arity] = hardNode(pp, i/arity, level, bsigma)
+		}
+	}
 
-		val0, ok0 := prev_layer_nodes[2*i]
-		val1, ok1 := prev_layer_nodes[(2*i)+1]
+	// the root has no siblings
+	if level == 0 {
+		return layer_nodes
+	}
 
-		var okp0, okp1 bool
-		if i%2 == 0 {
-			_, okp0 = prev_layer_nodes[(2*i)+2]
-			_, okp1 = prev_layer_nodes[(2*i)+3]
-		} else {
-			_, okp0 = prev_layer_nodes[(2*i)-2]
-			_, okp1 = prev_layer_nodes[(2*i)-1]
+	var soft_nodes = make(map[uint64]*TreeNode)
+	for i := range layer_nodes {
+		base := i &^ (arity - 1)
+		for k := uint64(0); k < arity; k++ {
+			if _, ok := layer_nodes[base+k]; !ok {
+				soft_nodes[base+k] = softNode(pp, base+k, level)
+			}
 		}
+	}
+	for i, node := range soft_nodes {
+		layer_nodes[i] = node
+	}
 
-		if ok0 && ok1 {
-			bsigma := val0.c0.Bytes()
-			bsigma = append(bsigma, val0.c1.Bytes()...)
-			bsigma = append(bsigma, val1.c0.Bytes()...)
-			bsigma = append(bsigma, val1.c1.Bytes()...)
-			bx := make([]byte, 8)
-			bl := make([]byte, 8)
-			binary.PutUvarint(bx, i)
-			binary.PutUvarint(bl, level)
-			bx = append(bx, bl...)
-			ra0, _ := pp.ps.ComputePrimaryPRF(bx, 32)
-			ra1, _ := pp.ps.ComputePrimaryPRF(ra0, 32)
-			var r0, r1 ristretto.Scalar
-			r0.Derive(ra0)
-			r1.Derive(ra1)
-			c0, c1 := mc.HardCommit(&pp.h, bsigma, &r0, &r1)
-			layer_nodes[i] = NewNode(false, c0, c1, r0, r1)
-		} else if okp0 || okp1 {
-			bx := make([]byte, 8)
-			bl := make([]byte, 8)
-			binary.PutUvarint(bx, i)
-			binary.PutUvarint(bl, level)
-			bx = append(bx, bl...)
-			ra0, _ := pp.ps.ComputePrimaryPRF(bx, 32)
-			ra1, _ := pp.ps.ComputePrimaryPRF(ra0, 32)
-			var r0, r1 ristretto.Scalar
-			r0.Derive(ra0)
-			r1.Derive(ra1)
-			c0, c1 := mc.SoftCommit(&r0, &r1)
-			layer_nodes[i] = NewNode(true, c0, c1, r0, r1)
-		}
+	return layer_nodes
 
+}
+
+// Computes the depth and arity of a tree over a universe of max values with the given options.
+func treeShape(max uint64, opts TreeOptions) (uint64, uint64, error) {
+	arity := opts.Arity
+	if arity == 0 {
+		arity = 2
+	}
+	if !ValidArity(arity) {
+		return 0, 0, ErrInvalidArity
 	}
 
-	return layer_nodes
+	levels := ComputeDepth(max, arity)
+	if opts.Depth != 0 {
+		if opts.Depth < levels {
+			return 0, 0, ErrDepthTooSmall
+		}
+		levels = opts.Depth
+	}
+	return levels, arity, nil
+}
 
+// Creates a new binary tree given a membership source.
+// Returns nil if the members of the source or the parameters are invalid (see NewTreeWithOptions).
+func NewTree(pp *PubVerPar, src MembershipSource) *Tree {
+	tree, _ := NewTreeWithOptions(pp, src, TreeOptions{})
+	return tree
 }
 
-// Creates a new tree given an EnumSet.
-// Calls ComputeLeaves and ComputeLayers.
-func NewTree(pp *PubVerPar, es *EnumSet) *Tree {
-	levels := ComputeNearestPowerof2(es.max)
+// Creates a new tree with the given options given a membership source.
+// Calls ComputeLeaves and ComputeLayers. Returns ErrNoProverKey if pp holds no PRF key.
+func NewTreeWithOptions(pp *PubVerPar, src MembershipSource, opts TreeOptions) (*Tree, error) {
+	if pp.kh == nil {
+		return nil, ErrNoProverKey
+	}
+	levels, arity, err := treeShape(src.Max(), opts)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(pp, src, levels, arity, nodeID)
+}
+
+// Builds a tree of the given depth and arity over the members of the source, which must be in capacity.
+// The leaf of member x commits to leaf(x, levels).
+func buildTree(pp *PubVerPar, src MembershipSource, levels uint64, arity uint64, leaf func(uint64, uint64) []byte) (*Tree, error) {
 	var tree = make(map[uint64]map[uint64]*TreeNode)
 
 	// compute the leaves of the tree
-	leaves := ComputeLeaves(pp, es, levels)
+	leaves, err := computeLeaves(pp, src, levels, arity, leaf)
+	if err != nil {
+		return nil, err
+	}
 	tree[levels] = leaves
 
 	// build the tree in a bottom up fashion
 	prev_layer_nodes := leaves
 	for i := int(levels) - 1; i >= 0; i-- {
-		layer_nodes := ComputeLayer(pp, uint64(i), prev_layer_nodes)
+		layer_nodes := ComputeLayer(pp, uint64(i), prev_layer_nodes, arity)
 		tree[uint64(i)] = layer_nodes
 		prev_layer_nodes = layer_nodes
 	}
 
 	// check for nil root
 	if len(tree[0]) == 0 {
-		bx := make([]byte, 8)
-		bl := make([]byte, 8)
-		binary.PutUvarint(bx, 0)
-		binary.PutUvarint(bl, 0)
-		bx = append(bx, bl...)
-		ra0, _ := pp.ps.ComputePrimaryPRF(bx, 32)
-		ra1, _ := pp.ps.ComputePrimaryPRF(ra0, 32)
-		var r0, r1 ristretto.Scalar
-		r0.Derive(ra0)
-		r1.Derive(ra1)
-		c0, c1 := mc.SoftCommit(&r0, &r1)
-		tree[0][0] = NewNode(true, c0, c1, r0, r1)
+		tree[0][0] = softNode(pp, 0, 0)
+	}
+
+	return &Tree{*tree[0][0], tree, levels, arity, nil}, nil
+}
+
+// Computes the index of the node on the path to x at a level of the tree.
+func (tree *Tree) index(x uint64, level uint64) uint64 {
+	return pathIndex(x, tree.levels, tree.arity, level)
+}
+
+// Reports whether x is a member of the set the tree commits to, i.e. its leaf is a hard commitment.
+func (tree *Tree) member(x uint64) bool {
+	leaf, ok := tree.tree[tree.levels][x]
+	return ok && !leaf.soft
+}
+
+// Lists the members of the set the tree commits to in ascending order.
+func (tree *Tree) members() []uint64 {
+	xs := []uint64{}
+	for x, leaf := range tree.tree[tree.levels] {
+		if !leaf.soft {
+			xs = append(xs, x)
+		}
 	}
+	slices.Sort(xs)
+	return xs
+}
 
-	return &Tree{*tree[0][0], tree, levels}
+// Computes the message the internal node i at a level commits to.
+func (tree *Tree) message(i uint64, level uint64) []byte {
+	return childrenMessage(tree.arity, groupComs(tree.tree[level+1], i*tree.arity, tree.arity))
+}
+
+// Builds an answer holding the commitments along the path to x.
+// Binary trees include the sibling commitment of every node, wider trees the vector commitment opening.
+func (tree *Tree) pathAnswer(a bool, x uint64) *Answer {
+	answer := &Answer{
+		answer:  a,
+		levels:  tree.levels,
+		arity:   tree.arity,
+		xcoms:   make(map[uint64]*Com),
+		sibcoms: make(map[uint64]*Com),
+		vopens:  make(map[uint64][][]byte),
+		opens:   make(map[uint64]*Open),
+		teases:  make(map[uint64]*Tease),
+	}
+	for j := uint64(1); j <= tree.levels; j++ {
+		xi := tree.index(x, j)
+		answer.xcoms[j] = tree.tree[j][xi].com()
+		if tree.arity == 2 {
+			answer.sibcoms[j] = tree.tree[j][xi^1].com()
+		} else {
+			answer.vopens[j] = VectorOpen(groupComs(tree.tree[j], xi, tree.arity), xi&(tree.arity-1))
+		}
+	}
+	return answer
 }
 
 // Information to open a commitment.
@@ -182,117 +383,65 @@ type Tease = ristretto.Scalar
 
 // Computes an authentication path in the tree for an element in the set.
 func MemberPath(tree *Tree, pp *PubVerPar, x uint64) *Answer {
-	var opens = make(map[uint64]*Open)
-	var xcoms = make(map[uint64]*Com)
-	var sibcoms = make(map[uint64]*Com)
-	var teases = make(map[uint64]*Tease)
-	for i := uint64(0); i <= tree.levels; i++ {
-		j := tree.levels - i
-		xi := x >> i
-		opens[j] = &Open{tree.tree[j][xi].r0, tree.tree[j][xi].r1}
-		if j >= 1 {
-			xcoms[j] = &Com{tree.tree[j][xi].c0, tree.tree[j][xi].c1}
-			sibcoms[j] = &Com{tree.tree[j][xi^1].c0, tree.tree[j][xi^1].c1}
-		}
+	answer := tree.pathAnswer(true, x)
+	answer.prefix = tree.levels - tree.leafLevel(x)
+	for j := uint64(0); j <= tree.levels; j++ {
+		val := tree.tree[j][tree.index(x, j)]
+		answer.opens[j] = &Open{val.r0, val.r1}
 	}
-
-	return &Answer{true, tree.levels, xcoms, sibcoms, opens, teases}
+	return answer
 }
 
 // Computes an authentication path in the tree for an element not in the set.
+//
+// Every node missing along the path is the soft commitment derived for its position, teased to the message
+// of its children (or "bot" at the leaf). Missing nodes therefore commit to the same value whichever element
+// is queried, and are computed into a copy of the path rather than added to the tree: the tree is never
+// modified and queries can run concurrently.
 func NonMemberPath(tree *Tree, pp *PubVerPar, x uint64) *Answer {
-	for i := uint64(0); i <= tree.levels-1; i++ {
-		j := tree.levels - i
-		xi := x >> i
-		_, okxi := tree.tree[j][xi]
-		_, okxip := tree.tree[j][xi^1]
-
-		if !okxi {
-			if j == tree.levels {
-				bx := make([]byte, 8)
-				bl := make([]byte, 8)
-				binary.PutUvarint(bx, xi)
-				binary.PutUvarint(bl, j)
-				bx = append(bx, bl...)
-				ra0, _ := pp.ps.ComputePrimaryPRF(bx, 32)
-				ra1, _ := pp.ps.ComputePrimaryPRF(ra0, 32)
-				var r0, r1 ristretto.Scalar
-				r0.Derive(ra0)
-				r1.Derive(ra1)
-				c0, c1 := mc.HardCommit(&pp.h, []byte("bot"), &r0, &r1)
-				tree.tree[j][xi] = NewNode(false, c0, c1, r0, r1)
-
-			} else {
-				val0 := tree.tree[j+1][2*xi]
-				val1 := tree.tree[j+1][(2*xi)+1]
-				bsigma := val0.c0.Bytes()
-				bsigma = append(bsigma, val0.c1.Bytes()...)
-				bsigma = append(bsigma, val1.c0.Bytes()...)
-				bsigma = append(bsigma, val1.c1.Bytes()...)
-				bx := make([]byte, 8)
-				bl := make([]byte, 8)
-				binary.PutUvarint(bx, xi)
-				binary.PutUvarint(bl, j)
-				bx = append(bx, bl...)
-				ra0, _ := pp.ps.ComputePrimaryPRF(bx, 32)
-				ra1, _ := pp.ps.ComputePrimaryPRF(ra0, 32)
-				var r0, r1 ristretto.Scalar
-				r0.Derive(ra0)
-				r1.Derive(ra1)
-				c0, c1 := mc.HardCommit(&pp.h, bsigma, &r0, &r1)
-				tree.tree[j][xi] = NewNode(false, c0, c1, r0, r1)
-			}
-		}
-
-		if !okxip {
-			bxip := make([]byte, 8)
-			bl := make([]byte, 8)
-			binary.PutUvarint(bxip, xi^1)
-			binary.PutUvarint(bl, j)
-			bxip = append(bxip, bl...)
-			ra0, _ := pp.ps.ComputePrimaryPRF(bxip, 32)
-			ra1, _ := pp.ps.ComputePrimaryPRF(ra0, 32)
-			var r0, r1 ristretto.Scalar
-			r0.Derive(ra0)
-			r1.Derive(ra1)
-			c0, c1 := mc.SoftCommit(&r0, &r1)
-			tree.tree[j][xi^1] = NewNode(true, c0, c1, r0, r1)
-
-		}
-	}
+	path := tree.pathCopy(pp, x)
 
 	// build answer
-	var opens = make(map[uint64]*Open)
-	var xcoms = make(map[uint64]*Com)
-	var sibcoms = make(map[uint64]*Com)
-	var teases = make(map[uint64]*Tease)
-	for i := uint64(0); i <= tree.levels; i++ {
-		j := tree.levels - i
-		xi := x >> i
-		val := tree.tree[j][xi]
+	answer := path.pathAnswer(false, x)
+	for j := uint64(0); j <= path.levels; j++ {
+		xi := path.index(x, j)
+		val := path.tree[j][xi]
 		var r ristretto.Scalar
 
 		if val.soft {
-			if j == tree.levels {
+			if j == path.levels {
 				r = mc.SoftTease([]byte("bot"), &val.r0, &val.r1)
 			} else {
-				bsigma := tree.tree[j+1][2*xi].c0.Bytes()
-				bsigma = append(bsigma, tree.tree[j+1][2*xi].c1.Bytes()...)
-				bsigma = append(bsigma, tree.tree[j+1][2*xi+1].c0.Bytes()...)
-				bsigma = append(bsigma, tree.tree[j+1][2*xi+1].c1.Bytes()...)
-				r = mc.SoftTease(bsigma, &val.r0, &val.r1)
+				r = mc.SoftTease(path.message(xi, j), &val.r0, &val.r1)
 			}
 		} else {
-			r = tree.tree[j][xi].r0
-		}
-		teases[j] = &r
-		if j >= 1 {
-			xcoms[j] = &Com{tree.tree[j][xi].c0, tree.tree[j][xi].c1}
-			sibcoms[j] = &Com{tree.tree[j][xi^1].c0, tree.tree[j][xi^1].c1}
+			r = val.r0
 		}
+		answer.teases[j] = &r
 	}
 
-	return &Answer{false, tree.levels, xcoms, sibcoms, opens, teases}
+	return answer
+}
+
+// Copies the nodes along the path to x and their siblings into a tree of their own.
+// Nodes missing from the tree are the soft commitments derived for their position; the tree is not modified.
+func (tree *Tree) pathCopy(pp *PubVerPar, x uint64) *Tree {
+	path := &Tree{tree.root, make(map[uint64]map[uint64]*TreeNode), tree.levels, tree.arity, tree.grown}
+	path.tree[0] = map[uint64]*TreeNode{0: tree.tree[0][0]}
+
+	for j := tree.levels; j >= 1; j-- {
+		base := tree.index(x, j) &^ (tree.arity - 1)
+		path.tree[j] = make(map[uint64]*TreeNode)
+
+		for k := uint64(0); k < tree.arity; k++ {
+			node, ok := tree.tree[j][base+k]
+			if !ok {
+				node = tree.softNode(pp, base+k, j)
+			}
+			path.tree[j][base+k] = node
+		}
+	}
+	return path
 }
 
 // Computes an authentication path for element x.
@@ -307,131 +456,16 @@ func (tree *Tree) Path(pp *PubVerPar, x uint64, a bool) *Answer {
 
 // Verifies a hard commitment path.
 func VerifyOpen(pp *PubVerPar, com Com, x uint64, answer *Answer) bool {
-	// verify all internal tree nodes
-	for i := uint64(1); i <= answer.levels-1; i++ {
-		c := answer.xcoms[i]
-		pi := answer.opens[i]
-		vx := answer.xcoms[i+1]
-		vs := answer.sibcoms[i+1]
-		vxleft := x >> (answer.levels - (i + 1)) % 2
-
-		if vxleft == 0 {
-			bsigma := vx.c0.Bytes()
-			bsigma = append(bsigma, vx.c1.Bytes()...)
-			bsigma = append(bsigma, vs.c0.Bytes()...)
-			bsigma = append(bsigma, vs.c1.Bytes()...)
-			if !mc.VerOpen(&pp.h, &c.c0, &c.c1, bsigma, &pi.r0, &pi.r1) {
-				return false
-			}
-		} else {
-			bsigma := vs.c0.Bytes()
-			bsigma = append(bsigma, vs.c1.Bytes()...)
-			bsigma = append(bsigma, vx.c0.Bytes()...)
-			bsigma = append(bsigma, vx.c1.Bytes()...)
-			if !mc.VerOpen(&pp.h, &c.c0, &c.c1, bsigma, &pi.r0, &pi.r1) {
-				return false
-			}
-		}
-	}
-
-	// check root commit
-	pi := answer.opens[0]
-	vx := answer.xcoms[1]
-	vs := answer.sibcoms[1]
-	vxleft := x >> (answer.levels - 1) % 2
-	if vxleft == 0 {
-		bsigma := vx.c0.Bytes()
-		bsigma = append(bsigma, vx.c1.Bytes()...)
-		bsigma = append(bsigma, vs.c0.Bytes()...)
-		bsigma = append(bsigma, vs.c1.Bytes()...)
-		if !mc.VerOpen(&pp.h, &com.c0, &com.c1, bsigma, &pi.r0, &pi.r1) {
-			return false
-		}
-	} else {
-		bsigma := vs.c0.Bytes()
-		bsigma = append(bsigma, vs.c1.Bytes()...)
-		bsigma = append(bsigma, vx.c0.Bytes()...)
-		bsigma = append(bsigma, vx.c1.Bytes()...)
-		if !mc.VerOpen(&pp.h, &com.c0, &com.c1, bsigma, &pi.r0, &pi.r1) {
-			return false
-		}
-	}
-
-	// check x commit
-	bx := make([]byte, 8)
-	bl := make([]byte, 8)
-	binary.PutUvarint(bx, x)
-	binary.PutUvarint(bl, answer.levels)
-	bx = append(bx, bl...)
-	cx := answer.xcoms[answer.levels]
-	pix := answer.opens[answer.levels]
-	return mc.VerOpen(&pp.h, &cx.c0, &cx.c1, bx, &pix.r0, &pix.r1)
+	return verify.VerifyOpen(pp.verifiable(), com.verifiable(), x, answer.verifiable())
 }
 
 // Verifies a soft commitment path.
 func VerifyTease(com Com, x uint64, answer *Answer) bool {
-	// verify all internal tree nodes
-
-	for i := uint64(1); i < answer.levels; i++ {
-		c := answer.xcoms[i]
-		tau := answer.teases[i]
-		vx := answer.xcoms[i+1]
-		vs := answer.sibcoms[i+1]
-		vxleft := x >> (answer.levels - (i + 1)) % 2
-		if vxleft == 0 {
-			bsigma := vx.c0.Bytes()
-			bsigma = append(bsigma, vx.c1.Bytes()...)
-			bsigma = append(bsigma, vs.c0.Bytes()...)
-			bsigma = append(bsigma, vs.c1.Bytes()...)
-			if !mc.VerTease(&c.c0, &c.c1, bsigma, tau) {
-				return false
-			}
-		} else {
-			bsigma := vs.c0.Bytes()
-			bsigma = append(bsigma, vs.c1.Bytes()...)
-			bsigma = append(bsigma, vx.c0.Bytes()...)
-			bsigma = append(bsigma, vx.c1.Bytes()...)
-			if !mc.VerTease(&c.c0, &c.c1, bsigma, tau) {
-				return false
-			}
-		}
-	}
-
-	// check root commit
-	tau := answer.teases[0]
-	vx := answer.xcoms[1]
-	vs := answer.sibcoms[1]
-	vxleft := x >> (answer.levels - 1) % 2
-	if vxleft == 0 {
-		bsigma := vx.c0.Bytes()
-		bsigma = append(bsigma, vx.c1.Bytes()...)
-		bsigma = append(bsigma, vs.c0.Bytes()...)
-		bsigma = append(bsigma, vs.c1.Bytes()...)
-		if !mc.VerTease(&com.c0, &com.c1, bsigma, tau) {
-			return false
-		}
-	} else {
-		bsigma := vs.c0.Bytes()
-		bsigma = append(bsigma, vs.c1.Bytes()...)
-		bsigma = append(bsigma, vx.c0.Bytes()...)
-		bsigma = append(bsigma, vx.c1.Bytes()...)
-		if !mc.VerTease(&com.c0, &com.c1, bsigma, tau) {
-			return false
-		}
-	}
-
-	// check x commit
-	cx := answer.xcoms[answer.levels]
-	taux := answer.teases[answer.levels]
-	return mc.VerTease(&cx.c0, &cx.c1, []byte("bot"), taux)
+	return verify.VerifyTease(com.verifiable(), x, answer.verifiable())
 }
 
 // Verifies an authentication path for element x.
 // Calls either VerifyOpen or VerifyTease.
 func VerifyPath(pp *PubVerPar, com Com, x uint64, answer *Answer) bool {
-	if answer.answer {
-		return VerifyOpen(pp, com, x, answer)
-	} else {
-		return VerifyTease(com, x, answer)
-	}
+	return verify.VerifyPath(pp.verifiable(), com.verifiable(), x, answer.verifiable())
 }
@@ -0,0 +1,58 @@
+package zks
+
+import (
+	"github.com/smarky7cd/ZKS/verify"
+)
+
+// Nodes of a q-ary tree (q > 2) commit to their children through a vector commitment.
+// The commitments of the q children are hashed into a Merkle tree and the node commits to its root,
+// so a single child can be opened with log2(q) hashes instead of all q-1 sibling commitments.
+
+// Hashes a commitment into a leaf of the vector commitment.
+func vectorLeaf(c *Com) []byte {
+	v := c.verifiable()
+	return verify.VectorLeaf(&v)
+}
+
+// Computes the layers of the Merkle tree over a power-of-two number of commitments, leaves first.
+func vectorLayers(coms []*Com) [][][]byte {
+	layer := make([][]byte, len(coms))
+	for i, c := range coms {
+		layer[i] = vectorLeaf(c)
+	}
+
+	layers := [][][]byte{layer}
+	for len(layer) > 1 {
+		next := make([][]byte, len(layer)/2)
+		for i := range next {
+			next[i] = verify.VectorNode(layer[2*i], layer[(2*i)+1])
+		}
+		layers = append(layers, next)
+		layer = next
+	}
+	return layers
+}
+
+// Computes the vector commitment to a power-of-two number of commitments.
+func VectorCommit(coms []*Com) []byte {
+	layers := vectorLayers(coms)
+	return layers[len(layers)-1][0]
+}
+
+// Computes the opening of the vector commitment at position pos.
+// The opening is the list of sibling hashes from the leaf up to the root.
+func VectorOpen(coms []*Com, pos uint64) [][]byte {
+	layers := vectorLayers(coms)
+	var opening [][]byte
+	for _, layer := range layers[:len(layers)-1] {
+		opening = append(opening, layer[pos^1])
+		pos >>= 1
+	}
+	return opening
+}
+
+// Recomputes the vector commitment from the commitment c at position pos and its opening.
+func VectorRoot(c *Com, pos uint64, opening [][]byte) []byte {
+	v := c.verifiable()
+	return verify.VectorRoot(&v, pos, opening)
+}
@@ -0,0 +1,184 @@
+package verify
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/bwesterb/go-ristretto"
+)
+
+// ErrMalformed is returned when decoding a value that doesn't have the expected shape.
+var ErrMalformed = errors.New("verify: malformed encoding")
+
+// Binary encodings are fixed-size concatenations of the 32-byte encodings of points and scalars,
+// with lengths as uvarints. They are the binary encodings of the zks package.
+
+// A reader over a binary encoding. The first error sticks and all later reads return zero values.
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+func (r *binaryReader) next(n int) []byte {
+	if r.err != nil || len(r.data) < n {
+		r.err = ErrMalformed
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *binaryReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = ErrMalformed
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *binaryReader) point() ristretto.Point {
+	var p ristretto.Point
+	if err := p.UnmarshalBinary(r.next(32)); err != nil && r.err == nil {
+		r.err = ErrMalformed
+	}
+	return p
+}
+
+func (r *binaryReader) scalar() ristretto.Scalar {
+	var s ristretto.Scalar
+	s.UnmarshalBinary(r.next(32))
+	return s
+}
+
+func (r *binaryReader) com() Com {
+	c0 := r.point()
+	c1 := r.point()
+	return Com{c0, c1}
+}
+
+// Fails unless the whole encoding was read.
+func (r *binaryReader) done() error {
+	if r.err == nil && len(r.data) != 0 {
+		r.err = ErrMalformed
+	}
+	return r.err
+}
+
+// Encodes the verifier parameters (h) as 32 bytes.
+func (pp *Params) MarshalBinary() ([]byte, error) {
+	return pp.H.Bytes(), nil
+}
+
+// Decodes verifier parameters.
+func ParseParams(data []byte) (*Params, error) {
+	r := &binaryReader{data: data}
+	h := r.point()
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return &Params{h}, nil
+}
+
+// Encodes the commitment as 64 bytes.
+func (c Com) MarshalBinary() ([]byte, error) {
+	return append(c.C0.Bytes(), c.C1.Bytes()...), nil
+}
+
+// Decodes a commitment.
+func ParseCom(data []byte) (Com, error) {
+	r := &binaryReader{data: data}
+	c := r.com()
+	if err := r.done(); err != nil {
+		return Com{}, err
+	}
+	return c, nil
+}
+
+// Encodes the answer: a version byte, the membership byte, the levels and arity as uvarints,
+// the commitments of levels 1 to levels and the openings or teases of levels 0 to levels.
+// Answers with a prefix are version 2 and carry the prefix as a uvarint after the arity.
+func (a *Answer) MarshalBinary() ([]byte, error) {
+	buf := []byte{1, 0}
+	if a.Prefix != 0 {
+		buf[0] = 2
+	}
+	if a.Member {
+		buf[1] = 1
+	}
+	buf = binary.AppendUvarint(buf, a.Levels())
+	buf = binary.AppendUvarint(buf, a.Arity)
+	if a.Prefix != 0 {
+		buf = binary.AppendUvarint(buf, a.Prefix)
+	}
+
+	for _, step := range a.Path {
+		buf = append(buf, step.Com.C0.Bytes()...)
+		buf = append(buf, step.Com.C1.Bytes()...)
+		if a.Arity == 2 {
+			buf = append(buf, step.Sib.C0.Bytes()...)
+			buf = append(buf, step.Sib.C1.Bytes()...)
+		} else {
+			for _, h := range step.VOpen {
+				buf = append(buf, h...)
+			}
+		}
+	}
+	if a.Member {
+		for _, pi := range a.Opens {
+			buf = append(buf, pi.R0.Bytes()...)
+			buf = append(buf, pi.R1.Bytes()...)
+		}
+	} else {
+		for _, tau := range a.Teases {
+			buf = append(buf, tau.Bytes()...)
+		}
+	}
+	return buf, nil
+}
+
+// Decodes an answer.
+func ParseAnswer(data []byte) (*Answer, error) {
+	r := &binaryReader{data: data}
+	header := r.next(2)
+	levels := r.uvarint()
+	arity := r.uvarint()
+	var prefix uint64
+	if header[0] == 2 {
+		prefix = r.uvarint()
+	}
+	if r.err != nil || header[0] != 1 && (header[0] != 2 || prefix == 0) || header[1] > 1 || !ValidArity(arity) ||
+		levels > uint64(len(data)/32) || prefix > levels {
+		return nil, ErrMalformed
+	}
+
+	a := &Answer{Member: header[1] == 1, Arity: arity, Path: make([]Step, levels), Prefix: prefix}
+	for j := range a.Path {
+		a.Path[j].Com = r.com()
+		if arity == 2 {
+			a.Path[j].Sib = r.com()
+		} else {
+			for k := uint64(0); k < ArityBits(arity); k++ {
+				a.Path[j].VOpen = append(a.Path[j].VOpen, r.next(32))
+			}
+		}
+	}
+	for j := uint64(0); j <= levels; j++ {
+		if a.Member {
+			r0 := r.scalar()
+			r1 := r.scalar()
+			a.Opens = append(a.Opens, Open{r0, r1})
+		} else {
+			a.Teases = append(a.Teases, r.scalar())
+		}
+	}
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
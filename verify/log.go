@@ -0,0 +1,86 @@
+package verify
+
+import (
+	"bytes"
+)
+
+// Commitments published by a prover are appended to a Merkle hash log (RFC 9162), whose leaves are the hashes of
+// the commitments and whose inner nodes are hashed as in vector commitments. Inclusion proofs show a commitment is
+// an entry of the log, consistency proofs that a log only grew by appending entries.
+
+// Hashes a commitment into a leaf of the log.
+func LogLeaf(c *Com) []byte {
+	return VectorLeaf(c)
+}
+
+// Verifies that the commitment c is entry index of the log of the given size with root hash root.
+func VerifyInclusion(c *Com, index uint64, size uint64, proof [][]byte, root []byte) bool {
+	if index >= size {
+		return false
+	}
+	fn, sn := index, size-1
+	r := LogLeaf(c)
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn%2 == 1 || fn == sn {
+			r = VectorNode(p, r)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = VectorNode(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(r, root)
+}
+
+// Verifies that the log of the given size with root hash root extends the log of size oldSize with root hash oldRoot.
+func VerifyConsistency(oldSize uint64, size uint64, oldRoot []byte, root []byte, proof [][]byte) bool {
+	switch {
+	case oldSize > size:
+		return false
+	case oldSize == size:
+		return len(proof) == 0 && bytes.Equal(oldRoot, root)
+	case oldSize == 0:
+		// every log extends the empty log
+		return len(proof) == 0
+	}
+
+	// a power-of-two old log is a subtree of the new one and its root starts the proof
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([][]byte{oldRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return false
+	}
+
+	fn, sn := oldSize-1, size-1
+	for fn%2 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+		if fn%2 == 1 || fn == sn {
+			fr = VectorNode(c, fr)
+			sr = VectorNode(c, sr)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = VectorNode(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(fr, oldRoot) && bytes.Equal(sr, root)
+}
@@ -0,0 +1,289 @@
+// Package verify checks ZKS answers against a commitment.
+//
+// It holds the verifier side of the ZKS only and depends on nothing but ristretto and the
+// mercurial commitment verification, so clients and audit tools that never prove can verify
+// answers without importing the PRF (tink) or any transport. Values are decoded from the
+// binary encodings of the zks package with ParseParams, ParseCom and ParseAnswer.
+package verify
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+	"slices"
+
+	"github.com/bwesterb/go-ristretto"
+	mc "github.com/smarky7CD/go-dl-mercurial-commitments"
+)
+
+// The verifier parameters: the point h of the commitment scheme.
+type Params struct {
+	H ristretto.Point
+}
+
+// A commitment is two points on the EC.
+type Com struct {
+	C0 ristretto.Point
+	C1 ristretto.Point
+}
+
+// Information to open a commitment.
+type Open struct {
+	R0 ristretto.Scalar
+	R1 ristretto.Scalar
+}
+
+// The commitments of one level of the path to an element.
+// Com is the commitment of the node on the path. Binary trees carry the commitment of its sibling (Sib),
+// wider trees the vector commitment opening of Com among its siblings (VOpen, log2(arity) hashes).
+type Step struct {
+	Com   Com
+	Sib   Com
+	VOpen [][]byte
+}
+
+// An answer contains the boolean set-membership reply and the proof.
+// Path[j-1] holds the commitments of level j, from 1 below the root down to the leaf, so len(Path) is the depth of the tree.
+// Opens (members) or Teases (non-members) hold one entry per level from the root to the leaf.
+// Prefix is the number of levels the tree was grown by above the tree a member leaf was committed in
+// (see Link), so the leaf commits to its node ID at level Levels()-Prefix. It is zero for non-members.
+type Answer struct {
+	Member bool
+	Arity  uint64
+	Path   []Step
+	Opens  []Open
+	Teases []ristretto.Scalar
+	Prefix uint64
+}
+
+// The depth of the tree the answer was computed in.
+func (a *Answer) Levels() uint64 {
+	return uint64(len(a.Path))
+}
+
+// Reports whether arity is a power of two greater than one.
+func ValidArity(arity uint64) bool {
+	return arity >= 2 && arity&(arity-1) == 0
+}
+
+// The number of bits of an element consumed by each level of a tree with the given arity.
+func ArityBits(arity uint64) uint64 {
+	return uint64(bits.TrailingZeros64(arity))
+}
+
+// Computes the index of the node on the path to x at a level of a tree with the given depth and arity.
+func PathIndex(x uint64, levels uint64, arity uint64, level uint64) uint64 {
+	return x >> (ArityBits(arity) * (levels - level))
+}
+
+// Reports whether x is one of the leaves of a tree with the given depth and arity.
+// Elements beyond the leaves (at least arity^levels) have no path to the root and can't be proven.
+func InCapacity(x uint64, levels uint64, arity uint64) bool {
+	return PathIndex(x, levels, arity, 0) == 0
+}
+
+// Encodes a node index and its level. Used as PRF input and as the message of member leaves.
+func NodeID(x uint64, level uint64) []byte {
+	bx := make([]byte, 16)
+	binary.BigEndian.PutUint64(bx, x)
+	binary.BigEndian.PutUint64(bx[8:], level)
+	return bx
+}
+
+// The message the leaf of a non-member commits to.
+var Bot = []byte("bot")
+
+// Computes the message of a binary node: the concatenation of both children.
+func PairMessage(left *Com, right *Com) []byte {
+	bsigma := left.C0.Bytes()
+	bsigma = append(bsigma, left.C1.Bytes()...)
+	bsigma = append(bsigma, right.C0.Bytes()...)
+	bsigma = append(bsigma, right.C1.Bytes()...)
+	return bsigma
+}
+
+// Hashes a commitment into a leaf of the vector commitment of a q-ary node.
+func VectorLeaf(c *Com) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(c.C0.Bytes())
+	h.Write(c.C1.Bytes())
+	return h.Sum(nil)
+}
+
+// Hashes two children into an inner node of the vector commitment of a q-ary node.
+func VectorNode(left []byte, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Recomputes the vector commitment from the commitment c at position pos and its opening.
+func VectorRoot(c *Com, pos uint64, opening [][]byte) []byte {
+	v := VectorLeaf(c)
+	for _, sib := range opening {
+		if pos%2 == 0 {
+			v = VectorNode(v, sib)
+		} else {
+			v = VectorNode(sib, v)
+		}
+		pos >>= 1
+	}
+	return v
+}
+
+// Checks that an answer has the shape of a path in a tree, so verification never reads missing entries.
+func wellFormed(a *Answer) bool {
+	if a == nil || !ValidArity(a.Arity) || a.Prefix > a.Levels() {
+		return false
+	}
+	for _, step := range a.Path {
+		if a.Arity != 2 && uint64(len(step.VOpen)) != ArityBits(a.Arity) {
+			return false
+		}
+	}
+	if a.Member {
+		return len(a.Opens) == len(a.Path)+1
+	}
+	return len(a.Teases) == len(a.Path)+1
+}
+
+// Recomputes the message committed to by the node on the path to x at a level from the answer.
+func pathMessage(x uint64, level uint64, a *Answer) []byte {
+	step := &a.Path[level]
+	pos := PathIndex(x, a.Levels(), a.Arity, level+1) & (a.Arity - 1)
+
+	if a.Arity == 2 {
+		if pos == 0 {
+			return PairMessage(&step.Com, &step.Sib)
+		}
+		return PairMessage(&step.Sib, &step.Com)
+	}
+	return VectorRoot(&step.Com, pos, step.VOpen)
+}
+
+// The commitment on the path to x at a level of the answer, the root being com.
+func pathCom(com *Com, level uint64, a *Answer) *Com {
+	if level == 0 {
+		return com
+	}
+	return &a.Path[level-1].Com
+}
+
+// Verifies a hard commitment path.
+func VerifyOpen(pp *Params, com Com, x uint64, a *Answer) bool {
+	if !wellFormed(a) {
+		return false
+	}
+	return VerifyOpenMessage(pp, com, x, a, NodeID(x, a.Levels()-a.Prefix))
+}
+
+// Verifies a hard commitment path whose leaf commits to msg rather than to the node ID of x,
+// e.g. the leaf of a named set in a forest.
+func VerifyOpenMessage(pp *Params, com Com, x uint64, a *Answer, msg []byte) bool {
+	if !wellFormed(a) || !a.Member || !InCapacity(x, a.Levels()-a.Prefix, a.Arity) {
+		return false
+	}
+	levels := a.Levels()
+
+	// verify the root and all internal tree nodes
+	for i := uint64(0); i < levels; i++ {
+		c := pathCom(&com, i, a)
+		pi := &a.Opens[i]
+		if !mc.VerOpen(&pp.H, &c.C0, &c.C1, pathMessage(x, i, a), &pi.R0, &pi.R1) {
+			return false
+		}
+	}
+
+	// check x commit
+	cx := pathCom(&com, levels, a)
+	pix := &a.Opens[levels]
+	return mc.VerOpen(&pp.H, &cx.C0, &cx.C1, msg, &pix.R0, &pix.R1)
+}
+
+// Verifies a soft commitment path.
+func VerifyTease(com Com, x uint64, a *Answer) bool {
+	if !wellFormed(a) || a.Member || !InCapacity(x, a.Levels(), a.Arity) {
+		return false
+	}
+	levels := a.Levels()
+
+	// verify the root and all internal tree nodes
+	for i := uint64(0); i < levels; i++ {
+		c := pathCom(&com, i, a)
+		if !mc.VerTease(&c.C0, &c.C1, pathMessage(x, i, a), &a.Teases[i]) {
+			return false
+		}
+	}
+
+	// check x commit
+	cx := pathCom(&com, levels, a)
+	return mc.VerTease(&cx.C0, &cx.C1, Bot, &a.Teases[levels])
+}
+
+// Verifies an authentication path for element x.
+// Calls either VerifyOpen or VerifyTease.
+func VerifyPath(pp *Params, com Com, x uint64, a *Answer) bool {
+	if a == nil {
+		return false
+	}
+	if a.Member {
+		return VerifyOpen(pp, com, x, a)
+	}
+	return VerifyTease(com, x, a)
+}
+
+// A link proves that a commitment is the leftmost node len(Path) levels below another,
+// i.e. that a tree was grown from the tree of the first commitment by adding levels above its root.
+// Path[j-1] holds the commitments of level j as in an answer, Opens the openings of levels 0 to len(Path)-1.
+type Link struct {
+	Arity uint64
+	Path  []Step
+	Opens []Open
+}
+
+// Verifies that the link leads from the commitment com of the grown tree down to the commitment old.
+func VerifyLink(pp *Params, old Com, com Com, l *Link) bool {
+	if l == nil || len(l.Opens) != len(l.Path) {
+		return false
+	}
+	// the link is the top of the path to 0, without the leaf
+	a := &Answer{Member: true, Arity: l.Arity, Path: l.Path, Opens: append(slices.Clone(l.Opens), Open{})}
+	if !wellFormed(a) {
+		return false
+	}
+
+	levels := a.Levels()
+	for i := uint64(0); i < levels; i++ {
+		c := pathCom(&com, i, a)
+		pi := &l.Opens[i]
+		if !mc.VerOpen(&pp.H, &c.C0, &c.C1, pathMessage(0, i, a), &pi.R0, &pi.R1) {
+			return false
+		}
+	}
+	cx := pathCom(&com, levels, a)
+	return cx.C0.Equals(&old.C0) && cx.C1.Equals(&old.C1)
+}
+
+// Extends an answer computed in the tree the link starts from into an answer in the grown tree.
+// Return: the extended answer, nil if the answer is malformed or of another arity.
+func (l *Link) Extend(a *Answer) *Answer {
+	if l == nil || !wellFormed(a) || a.Arity != l.Arity || len(l.Opens) != len(l.Path) {
+		return nil
+	}
+	e := &Answer{Member: a.Member, Arity: a.Arity}
+	e.Path = append(slices.Clone(l.Path), a.Path...)
+	if a.Member {
+		e.Prefix = a.Prefix + uint64(len(l.Path))
+		e.Opens = append(slices.Clone(l.Opens), a.Opens...)
+	} else {
+		// the nodes of the link are hard, which are teased by their first scalar
+		for _, pi := range l.Opens {
+			e.Teases = append(e.Teases, pi.R0)
+		}
+		e.Teases = append(e.Teases, a.Teases...)
+	}
+	return e
+}
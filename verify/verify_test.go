@@ -0,0 +1,107 @@
+package verify_test
+
+import (
+	"testing"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/smarky7cd/ZKS/verify"
+	"github.com/stretchr/testify/assert"
+)
+
+// Decodes the verifier params, commitment and answer on x from their zks binary encodings.
+func decode(t *testing.T, pp *zks.PubVerPar, com zks.Com, a *zks.Answer) (*verify.Params, verify.Com, *verify.Answer) {
+	bpp, _ := pp.MarshalBinary()
+	bcom, _ := com.MarshalBinary()
+	ba, err := a.MarshalBinary()
+	assert.Nil(t, err)
+
+	vpp, err := verify.ParseParams(bpp)
+	assert.Nil(t, err)
+	vcom, err := verify.ParseCom(bcom)
+	assert.Nil(t, err)
+	va, err := verify.ParseAnswer(ba)
+	assert.Nil(t, err)
+
+	// the encodings round trip
+	bva, _ := va.MarshalBinary()
+	assert.Equal(t, ba, bva)
+	return vpp, vcom, va
+}
+
+func TestVerifyPath(t *testing.T) {
+	values := map[uint64]bool{0: true, 3: true, 9: true, 14: true}
+	pp := zks.Gen()
+
+	for _, arity := range []uint64{2, 4, 16} {
+		repr, com, err := zks.RepWithOptions(pp, zks.NewEnumSet(values, 16), zks.TreeOptions{Arity: arity})
+		assert.Nil(t, err)
+
+		for x := uint64(0); x < 16; x++ {
+			vpp, vcom, va := decode(t, pp, com, zks.Qry(pp, repr, x))
+			assert.Equal(t, values[x], va.Member, "answer for %d", x)
+			assert.True(t, verify.VerifyPath(vpp, vcom, x, va), "arity %d answer for %d", arity, x)
+			assert.False(t, verify.VerifyPath(vpp, vcom, (x+1)%16, va), "answer for %d used for another element", x)
+		}
+	}
+}
+
+func TestReject(t *testing.T) {
+	pp := zks.Gen()
+	repr, com := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{5: true}, 8))
+	vpp, vcom, member := decode(t, pp, com, zks.Qry(pp, repr, 5))
+	_, _, nonmember := decode(t, pp, com, zks.Qry(pp, repr, 4))
+
+	// a claim flipped to the other membership bit, or stripped of its proof, doesn't verify
+	member.Member = false
+	assert.False(t, verify.VerifyPath(vpp, vcom, 5, member))
+	nonmember.Member = true
+	assert.False(t, verify.VerifyPath(vpp, vcom, 4, nonmember))
+	assert.False(t, verify.VerifyPath(vpp, vcom, 5, nil))
+	assert.False(t, verify.VerifyPath(vpp, vcom, 5, &verify.Answer{Member: true, Arity: 2}))
+
+	// nor does an answer under another commitment
+	_, other := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{5: true, 6: true}, 8))
+	_, ocom, member := decode(t, pp, other, zks.Qry(pp, repr, 5))
+	assert.False(t, verify.VerifyPath(vpp, ocom, 5, member))
+
+	// truncated encodings are rejected
+	data, _ := zks.Qry(pp, repr, 5).MarshalBinary()
+	_, err := verify.ParseAnswer(data[:len(data)-1])
+	assert.ErrorIs(t, err, verify.ErrMalformed)
+	_, err = verify.ParseCom(data[:63])
+	assert.ErrorIs(t, err, verify.ErrMalformed)
+}
+
+func TestLink(t *testing.T) {
+	pp := zks.Gen()
+	repr, com := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{0: true, 5: true}, 8))
+	grown, gcom, _, err := repr.GrowUniverse(pp, 64)
+	assert.Nil(t, err)
+
+	// answers in the grown tree carry the prefix and round trip through the version 2 encoding
+	vpp, vgcom, member := decode(t, pp, gcom, zks.Qry(pp, grown, 5))
+	assert.Equal(t, uint64(3), member.Prefix)
+	assert.True(t, verify.VerifyPath(vpp, vgcom, 5, member))
+	_, _, nonmember := decode(t, pp, gcom, zks.Qry(pp, grown, 40))
+	assert.Equal(t, uint64(0), nonmember.Prefix)
+	assert.True(t, verify.VerifyPath(vpp, vgcom, 40, nonmember))
+
+	// the leaf is only opened at the depth it was committed at
+	member.Prefix = 2
+	assert.False(t, verify.VerifyPath(vpp, vgcom, 5, member))
+	member.Prefix = 4
+	assert.False(t, verify.VerifyPath(vpp, vgcom, 5, member))
+
+	// the link is the top of the path to 0, and extends the answers of the old tree
+	_, vcom, old := decode(t, pp, com, zks.Qry(pp, repr, 5))
+	_, _, zero := decode(t, pp, gcom, zks.Qry(pp, grown, 0))
+	link := &verify.Link{Arity: 2, Path: zero.Path[:3], Opens: zero.Opens[:3]}
+	assert.True(t, verify.VerifyLink(vpp, vcom, vgcom, link))
+	assert.True(t, verify.VerifyPath(vpp, vgcom, 5, link.Extend(old)))
+	assert.False(t, verify.VerifyLink(vpp, vgcom, vcom, link))
+	assert.Nil(t, link.Extend(&verify.Answer{Member: true, Arity: 4}))
+
+	// a shorter link doesn't reach the old root
+	short := &verify.Link{Arity: 2, Path: zero.Path[:2], Opens: zero.Opens[:2]}
+	assert.False(t, verify.VerifyLink(vpp, vcom, vgcom, short))
+}
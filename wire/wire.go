@@ -0,0 +1,89 @@
+// Package wire defines the HTTP bodies exchanged by package server and package client.
+//
+// Bodies are JSON, or with media type BinaryType the binary encodings of the zks package. A binary batch
+// request is a uvarint count followed by 8-byte big-endian elements, a binary batch response a uvarint count
+// followed by uvarint-length-prefixed answers.
+package wire
+
+import (
+	"encoding/binary"
+
+	zks "github.com/smarky7cd/ZKS"
+)
+
+// The media type of binary bodies.
+const BinaryType = "application/octet-stream"
+
+// MaxBatch is the largest number of elements a batch query may ask for.
+const MaxBatch = 1024
+
+// The body of a JSON batch query.
+type BatchRequest struct {
+	Xs []uint64 `json:"xs"`
+}
+
+// The body of a JSON batch response, answers in the order of the request.
+type BatchResponse struct {
+	Answers []*zks.Answer `json:"answers"`
+}
+
+// Encodes the elements of a batch query as a binary body.
+func AppendBatchRequest(buf []byte, xs []uint64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(xs)))
+	for _, x := range xs {
+		buf = binary.BigEndian.AppendUint64(buf, x)
+	}
+	return buf
+}
+
+// Decodes the elements of a binary batch query, zks.ErrMalformed if it is malformed or asks for more than MaxBatch.
+func ParseBatchRequest(body []byte) ([]uint64, error) {
+	n, k := binary.Uvarint(body)
+	if k <= 0 || n > MaxBatch || uint64(len(body)-k) != 8*n {
+		return nil, zks.ErrMalformed
+	}
+	xs := make([]uint64, n)
+	for i := range xs {
+		xs[i] = binary.BigEndian.Uint64(body[k+8*i:])
+	}
+	return xs, nil
+}
+
+// Encodes the answers of a batch query as a binary body.
+func AppendBatchResponse(buf []byte, answers []*zks.Answer) ([]byte, error) {
+	buf = binary.AppendUvarint(buf, uint64(len(answers)))
+	for _, a := range answers {
+		data, err := a.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(data)))
+		buf = append(buf, data...)
+	}
+	return buf, nil
+}
+
+// Decodes the answers of a binary batch response, zks.ErrMalformed if it is malformed or holds more than MaxBatch.
+func ParseBatchResponse(body []byte) ([]*zks.Answer, error) {
+	n, k := binary.Uvarint(body)
+	if k <= 0 || n > MaxBatch {
+		return nil, zks.ErrMalformed
+	}
+	body = body[k:]
+	answers := make([]*zks.Answer, n)
+	for i := range answers {
+		size, k := binary.Uvarint(body)
+		if k <= 0 || uint64(len(body)-k) < size {
+			return nil, zks.ErrMalformed
+		}
+		answers[i] = new(zks.Answer)
+		if err := answers[i].UnmarshalBinary(body[k : k+int(size)]); err != nil {
+			return nil, err
+		}
+		body = body[k+int(size):]
+	}
+	if len(body) != 0 {
+		return nil, zks.ErrMalformed
+	}
+	return answers, nil
+}
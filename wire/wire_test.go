@@ -0,0 +1,40 @@
+package wire
+
+import (
+	"testing"
+
+	zks "github.com/smarky7cd/ZKS"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchBodies(t *testing.T) {
+	xs := []uint64{0, 5, 1 << 40}
+	got, err := ParseBatchRequest(AppendBatchRequest(nil, xs))
+	assert.Nil(t, err)
+	assert.Equal(t, xs, got)
+
+	pp := zks.Gen()
+	repr, com := zks.Rep(pp, zks.NewEnumSet(map[uint64]bool{5: true}, 8))
+	answers := []*zks.Answer{zks.Qry(pp, repr, 5), zks.Qry(pp, repr, 6)}
+	body, err := AppendBatchResponse(nil, answers)
+	assert.Nil(t, err)
+	parsed, err := ParseBatchResponse(body)
+	assert.Nil(t, err)
+	assert.Len(t, parsed, 2)
+	assert.True(t, zks.Vfy(pp, com, 5, parsed[0]) && parsed[0].Member())
+	assert.True(t, zks.Vfy(pp, com, 6, parsed[1]) && !parsed[1].Member())
+
+	// truncated, oversized and trailing bodies are malformed
+	for _, body := range [][]byte{
+		{},
+		{2, 0},
+		AppendBatchRequest(nil, make([]uint64, MaxBatch+1)),
+	} {
+		_, err := ParseBatchRequest(body)
+		assert.ErrorIs(t, err, zks.ErrMalformed)
+	}
+	for _, body := range [][]byte{{}, body[:len(body)-1], append(body, 0), {0xff, 0xff, 0x01}} {
+		_, err := ParseBatchResponse(body)
+		assert.ErrorIs(t, err, zks.ErrMalformed)
+	}
+}
@@ -5,19 +5,26 @@ import (
 	"github.com/google/tink/go/keyset"
 	"github.com/google/tink/go/prf"
 	mc "github.com/smarky7CD/go-dl-mercurial-commitments"
+	"github.com/smarky7cd/ZKS/verify"
 )
 
 // h is the randomly selected point on the EC used for the commitment scheme
 // ps is the randomly selected PRF
+// kh is the keyset of the PRF, kept so the prover key can be stored
+//
+// Verifiers only ever use h: parameters decoded from verifier params hold no PRF and can't be used to prove.
 type PubVerPar struct {
-	h  ristretto.Point
-	ps prf.Set
+	h   ristretto.Point
+	ps  prf.Set
+	kh  *keyset.Handle
+	log *CommitLog
 }
 
-// A ZKS representation is the tree and the underlying EnumSet.
+// A ZKS representation is the tree and the universe bound of the set.
+// Membership is read off the leaves of the tree, so the set itself is not kept.
 type Repr struct {
 	tree Tree
-	set  EnumSet
+	max  uint64
 }
 
 // A commitment is two points on the EC.
@@ -27,11 +34,17 @@ type Com struct {
 }
 
 // An answer contains the boolean set-membership reply and information used in the proof.
+// Binary trees carry the sibling commitments along the path (sibcoms),
+// wider trees carry the vector commitment openings along the path (vopens).
+// Answers for members of a grown tree (see Repr.GrowUniverse) carry the number of levels grown above their leaf (prefix).
 type Answer struct {
 	answer  bool
 	levels  uint64
+	arity   uint64
+	prefix  uint64
 	xcoms   map[uint64]*Com
 	sibcoms map[uint64]*Com
+	vopens  map[uint64][][]byte
 	opens   map[uint64]*Open
 	teases  map[uint64]*Tease
 }
@@ -39,22 +52,60 @@ type Answer struct {
 // Generate h (value used for commitments) and *ps (the PRF).
 func Gen() *PubVerPar {
 	h := mc.GeneratePublicParameters()
+	return newPubVerPar(h, newPRFKey())
+}
+
+// Generate a fresh HMAC-SHA256 PRF key.
+func newPRFKey() *keyset.Handle {
 	kh, _ := keyset.NewHandle(prf.HMACSHA256PRFKeyTemplate())
+	return kh
+}
+
+// Assemble public parameters from h and the keyset of the PRF.
+func newPubVerPar(h ristretto.Point, kh *keyset.Handle) *PubVerPar {
 	ps, _ := prf.NewPRFSet(kh)
-	return &PubVerPar{h, *ps}
+	return &PubVerPar{h: h, ps: *ps, kh: kh}
 }
 
-// Input: public parameters (h,ps) and an EnumSet.
-// Return: ZKS representation and a commitment to it.
-func Rep(pp *PubVerPar, es *EnumSet) (*Repr, Com) {
-	tree := NewTree(pp, es)
-	return &Repr{*tree, *es}, Com{tree.root.c0, tree.root.c1}
+// Input: public parameters (h,ps) and a membership source, e.g. an EnumSet.
+// Return: ZKS representation and a commitment to it, nil if the source yields invalid members, pp holds no PRF key
+// or the commitment can't be appended to the log of pp (see RepWithOptions).
+func Rep(pp *PubVerPar, src MembershipSource) (*Repr, Com) {
+	tree := NewTree(pp, src)
+	if tree == nil {
+		return nil, Com{}
+	}
+	com := Com{tree.root.c0, tree.root.c1}
+	if err := pp.Publish(com); err != nil {
+		return nil, Com{}
+	}
+	return &Repr{*tree, src.Max()}, com
+}
+
+// Input: public parameters (h,ps), a membership source and the options shaping the tree (e.g. its arity).
+// Return: ZKS representation and a commitment to it, or an error if the options or the members of the source are invalid,
+// pp holds no PRF key (ErrNoProverKey) or the commitment can't be appended to the log of pp (see PubVerPar.WithLog).
+func RepWithOptions(pp *PubVerPar, src MembershipSource, opts TreeOptions) (*Repr, Com, error) {
+	tree, err := NewTreeWithOptions(pp, src, opts)
+	if err != nil {
+		return nil, Com{}, err
+	}
+	com := Com{tree.root.c0, tree.root.c1}
+	if err := pp.Publish(com); err != nil {
+		return nil, Com{}, err
+	}
+	return &Repr{*tree, src.Max()}, com, nil
 }
 
 // Input: The public parameters (h,ps), a ZKS representation, and an element x.
 // Return: Answer struct containing set-membership response and a proof.
+// Elements beyond the leaves of the tree (see TreeOptions.Depth to cover every uint64) have no proof and yield nil,
+// as do parameters holding no PRF key (verifier params).
 func Qry(pp *PubVerPar, repr *Repr, x uint64) *Answer {
-	return repr.tree.Path(pp, x, repr.set.In(x))
+	if pp.kh == nil || !inCapacity(x, repr.tree.levels, repr.tree.arity) {
+		return nil
+	}
+	return repr.tree.Path(pp, x, repr.tree.member(x))
 }
 
 // Input: The public parameters (h,ps), a ZKS commitment, an element x that was queried, and the answer/proof struct.
@@ -62,3 +113,86 @@ func Qry(pp *PubVerPar, repr *Repr, x uint64) *Answer {
 func Vfy(pp *PubVerPar, com Com, x uint64, answer *Answer) bool {
 	return VerifyPath(pp, com, x, answer)
 }
+
+// Verification is implemented by the verify package, which has no PRF dependency.
+// The following convert the values of the ZKS to and from the ones it verifies.
+
+// The verifier parameters of pp.
+func (pp *PubVerPar) verifiable() *verify.Params {
+	return &verify.Params{H: pp.h}
+}
+
+// The commitment c as verified.
+func (c *Com) verifiable() verify.Com {
+	return verify.Com{C0: c.c0, C1: c.c1}
+}
+
+// Converts the answer into the one verified, nil if it does not have the shape of a path in a tree.
+func (a *Answer) verifiable() *verify.Answer {
+	if a == nil || !ValidArity(a.arity) {
+		return nil
+	}
+	v := &verify.Answer{Member: a.answer, Arity: a.arity, Prefix: a.prefix}
+	for j := uint64(1); j <= a.levels; j++ {
+		xcom := a.xcoms[j]
+		if xcom == nil {
+			return nil
+		}
+		step := verify.Step{Com: xcom.verifiable(), VOpen: a.vopens[j]}
+		if a.arity == 2 {
+			sibcom := a.sibcoms[j]
+			if sibcom == nil {
+				return nil
+			}
+			step.Sib = sibcom.verifiable()
+		}
+		v.Path = append(v.Path, step)
+	}
+	for j := uint64(0); j <= a.levels; j++ {
+		if a.answer {
+			pi := a.opens[j]
+			if pi == nil {
+				return nil
+			}
+			v.Opens = append(v.Opens, verify.Open{R0: pi.r0, R1: pi.r1})
+		} else {
+			tau := a.teases[j]
+			if tau == nil {
+				return nil
+			}
+			v.Teases = append(v.Teases, *tau)
+		}
+	}
+	return v
+}
+
+// Converts a verified answer back into an answer of the ZKS.
+func answerFrom(v *verify.Answer) *Answer {
+	a := &Answer{
+		answer:  v.Member,
+		levels:  v.Levels(),
+		arity:   v.Arity,
+		prefix:  v.Prefix,
+		xcoms:   make(map[uint64]*Com),
+		sibcoms: make(map[uint64]*Com),
+		vopens:  make(map[uint64][][]byte),
+		opens:   make(map[uint64]*Open),
+		teases:  make(map[uint64]*Tease),
+	}
+	for i, step := range v.Path {
+		j := uint64(i) + 1
+		a.xcoms[j] = &Com{step.Com.C0, step.Com.C1}
+		if v.Arity == 2 {
+			a.sibcoms[j] = &Com{step.Sib.C0, step.Sib.C1}
+		} else {
+			a.vopens[j] = step.VOpen
+		}
+	}
+	for i := range v.Opens {
+		a.opens[uint64(i)] = &Open{v.Opens[i].R0, v.Opens[i].R1}
+	}
+	for i := range v.Teases {
+		a.teases[uint64(i)] = &v.Teases[i]
+	}
+	return a
+}
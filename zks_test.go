@@ -1,10 +1,18 @@
 package zks
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -406,6 +414,555 @@ func TestCorrectness3(t *testing.T) {
 	}
 }
 
+func TestCorrectnessArity(t *testing.T) {
+
+	for _, arity := range []uint64{4, 8, 16} {
+		max_value := uint64(randRange(8, 1024))
+
+		var values = make(map[uint64]bool)
+		for i := uint64(0); i < max_value; i++ {
+			values[i] = rand.Float64() <= 0.3
+		}
+
+		set := NewEnumSet(values, max_value)
+
+		pp := Gen()
+
+		repr, com, err := RepWithOptions(pp, set, TreeOptions{Arity: arity})
+		assert.Nil(t, err)
+
+		for i := uint64(0); i < max_value; i++ {
+			a := Qry(pp, repr, i)
+			assert.Equal(t, values[i], a.answer, "answer should match membership.")
+			assert.Equal(t, ComputeDepth(max_value, arity), a.levels)
+			v := Vfy(pp, com, i, a)
+			assert.True(t, v, "v should be true.")
+		}
+	}
+}
+
+func TestInvalidArity(t *testing.T) {
+	pp := Gen()
+	set := NewEnumSet(map[uint64]bool{1: true}, 16)
+
+	for _, arity := range []uint64{1, 3, 6, 12} {
+		_, _, err := RepWithOptions(pp, set, TreeOptions{Arity: arity})
+		assert.ErrorIs(t, err, ErrInvalidArity)
+	}
+}
+
+func TestWrongElementArity(t *testing.T) {
+	values := map[uint64]bool{3: true, 17: true, 42: true}
+	set := NewEnumSet(values, 64)
+	pp := Gen()
+
+	for _, arity := range []uint64{2, 4, 8} {
+		repr, com, _ := RepWithOptions(pp, set, TreeOptions{Arity: arity})
+
+		// a membership proof for 17 must not verify for any other element
+		a := Qry(pp, repr, 17)
+		assert.False(t, Vfy(pp, com, 16, a), "v should be false.")
+		assert.False(t, Vfy(pp, com, 42, a), "v should be false.")
+
+		// nor may a non-membership proof verify for a member
+		b := Qry(pp, repr, 18)
+		assert.False(t, Vfy(pp, com, 17, b), "v should be false.")
+	}
+}
+
+func TestNonMemberPaths(t *testing.T) {
+	pp := Gen()
+
+	// the nodes missing from the tree commit to the same value whichever non-member is queried
+	repr, com := Rep(pp, NewEnumSet(map[uint64]bool{0: true}, 16))
+	a8, a9 := Qry(pp, repr, 8), Qry(pp, repr, 9)
+	for j := uint64(1); j < a8.levels; j++ {
+		assert.True(t, a8.xcoms[j].Equals(*a9.xcoms[j]), "level %d", j)
+		assert.True(t, a8.sibcoms[j].Equals(*a9.sibcoms[j]), "level %d", j)
+	}
+	assert.True(t, a8.xcoms[a8.levels].Equals(*a9.sibcoms[a9.levels]))
+	assert.True(t, a9.xcoms[a9.levels].Equals(*a8.sibcoms[a8.levels]))
+	for x := range uint64(16) {
+		assert.True(t, Vfy(pp, com, x, Qry(pp, repr, x)), "v should be true.")
+	}
+
+	repr, _, _ = RepWithOptions(pp, NewEnumSet(map[uint64]bool{0: true}, 16), TreeOptions{Arity: 4})
+	a8, a9 = Qry(pp, repr, 8), Qry(pp, repr, 9)
+	assert.True(t, a8.xcoms[1].Equals(*a9.xcoms[1]))
+	assert.Equal(t, a8.vopens[1], a9.vopens[1])
+}
+
+func TestFixedDepth(t *testing.T) {
+	pp := Gen()
+
+	for _, opts := range []TreeOptions{{Depth: 64}, {Depth: 256}, {Arity: 16, Depth: 16}} {
+		for _, max_value := range []uint64{16, 1000} {
+			values := map[uint64]bool{0: true, 7: true, 15: true}
+			repr, com, err := RepWithOptions(pp, NewEnumSet(values, max_value), opts)
+			assert.Nil(t, err)
+
+			// every answer has the fixed depth, whatever the universe
+			for _, x := range []uint64{0, 1, 7, 15, 16, 999, 1 << 40} {
+				a := Qry(pp, repr, x)
+				assert.Equal(t, values[x], a.answer)
+				assert.Equal(t, opts.Depth, a.levels)
+				assert.True(t, Vfy(pp, com, x, a), "v should be true.")
+			}
+		}
+	}
+
+	_, _, err := RepWithOptions(pp, NewEnumSet(map[uint64]bool{}, 1000), TreeOptions{Depth: 9})
+	assert.ErrorIs(t, err, ErrDepthTooSmall)
+	_, _, err = RepWithOptions(pp, NewEnumSet(map[uint64]bool{}, 1000), TreeOptions{Arity: 4, Depth: 4})
+	assert.ErrorIs(t, err, ErrDepthTooSmall)
+	_, err = NewSimulator(1000, TreeOptions{Depth: 9})
+	assert.ErrorIs(t, err, ErrDepthTooSmall)
+}
+
+func TestComputeNearestPowerof2(t *testing.T) {
+	cases := map[uint64]uint64{
+		0:                  0,
+		1:                  0,
+		2:                  1,
+		3:                  2,
+		4:                  2,
+		5:                  3,
+		1 << 32:            32,
+		(1 << 32) + 1:      33,
+		(1 << 53) + 1:      54,
+		(1 << 63) - 1:      63,
+		1 << 63:            63,
+		(1 << 63) + 1:      64,
+		math.MaxUint64 - 1: 64,
+		math.MaxUint64:     64,
+	}
+	for n, levels := range cases {
+		assert.Equal(t, levels, ComputeNearestPowerof2(n), "levels for %d", n)
+	}
+	assert.Equal(t, uint64(32), ComputeDepth(math.MaxUint64, 4))
+	assert.Equal(t, uint64(2), ComputeDepth(9, 8))
+}
+
+// Checks every given element answers as the set does and verifies.
+func checkQueries(t *testing.T, pp *PubVerPar, repr *Repr, com Com, set *EnumSet, xs []uint64) {
+	for _, x := range xs {
+		a := Qry(pp, repr, x)
+		assert.NotNil(t, a, "answer for %d", x)
+		assert.Equal(t, set.In(x), a.answer, "answer for %d", x)
+		assert.True(t, Vfy(pp, com, x, a), "v should be true for %d.", x)
+	}
+}
+
+func TestDegenerateUniverses(t *testing.T) {
+	pp := Gen()
+
+	// empty universe
+	set := NewEnumSet(map[uint64]bool{}, 0)
+	set.Add(0)
+	repr, com := Rep(pp, set)
+	checkQueries(t, pp, repr, com, set, []uint64{0})
+	assert.Nil(t, Qry(pp, repr, 1))
+	assert.False(t, Vfy(pp, com, 1, nil))
+
+	// single-element universes, with and without the element
+	for _, member := range []bool{false, true} {
+		set := NewEnumSet(map[uint64]bool{0: member}, 1)
+		repr, com := Rep(pp, set)
+		a := Qry(pp, repr, 0)
+		assert.Equal(t, uint64(0), a.levels)
+		checkQueries(t, pp, repr, com, set, []uint64{0})
+		assert.Nil(t, Qry(pp, repr, 1))
+	}
+
+	// empty sets
+	for _, max_value := range []uint64{2, 1000, math.MaxUint64} {
+		set := NewEnumSet(map[uint64]bool{}, max_value)
+		repr, com := Rep(pp, set)
+		checkQueries(t, pp, repr, com, set, []uint64{0, 1, max_value - 1})
+	}
+}
+
+func TestPowerOfTwoUniverses(t *testing.T) {
+	pp := Gen()
+
+	for _, arity := range []uint64{2, 4} {
+		for _, max_value := range []uint64{2, 4, 16, 64} {
+			set := NewEnumSet(map[uint64]bool{0: true, max_value - 1: true}, max_value)
+			repr, com, _ := RepWithOptions(pp, set, TreeOptions{Arity: arity})
+			checkQueries(t, pp, repr, com, set, []uint64{0, 1, max_value - 2, max_value - 1})
+
+			// the universe fills the tree when max is a power of the arity
+			if ComputeDepth(max_value, arity)*arityBits(arity) == ComputeNearestPowerof2(max_value) {
+				a := Qry(pp, repr, max_value)
+				assert.Nil(t, a, "no proof beyond the leaves")
+			}
+		}
+	}
+
+	// elements beyond max but within the leaves are non-members
+	set := NewEnumSet(map[uint64]bool{3: true}, 10)
+	repr, com := Rep(pp, set)
+	checkQueries(t, pp, repr, com, set, []uint64{3, 9, 10, 15})
+	assert.Nil(t, Qry(pp, repr, 16))
+
+	// a proof for an element within the leaves doesn't verify for one beyond them
+	a := Qry(pp, repr, 4)
+	assert.False(t, Vfy(pp, com, 4+16, a), "v should be false.")
+}
+
+func TestHugeUniverses(t *testing.T) {
+	pp := Gen()
+
+	for _, max_value := range []uint64{1<<63 + 1, math.MaxUint64 - 1, math.MaxUint64} {
+		top := max_value - 1
+		values := map[uint64]bool{0: true, 1 << 62: true, 1 << 63: true, top: true, top - 3: true}
+		set := NewEnumSet(values, max_value)
+
+		for _, arity := range []uint64{2, 16} {
+			repr, com, err := RepWithOptions(pp, set, TreeOptions{Arity: arity})
+			assert.Nil(t, err)
+			checkQueries(t, pp, repr, com, set, []uint64{0, 1, 1 << 62, 1<<63 - 1, 1 << 63, top - 3, top - 1, top, math.MaxUint64})
+
+			a := Qry(pp, repr, top)
+			assert.Equal(t, ComputeDepth(max_value, arity), a.levels)
+			assert.False(t, Vfy(pp, com, top-1, a), "v should be false.")
+		}
+	}
+
+	// the bound math.MaxUint64 stands for a universe of every uint64
+	set := NewEnumSet(map[uint64]bool{0: true, math.MaxUint64: true}, math.MaxUint64)
+	repr, com, err := RepWithOptions(pp, set, TreeOptions{})
+	assert.Nil(t, err)
+	checkQueries(t, pp, repr, com, set, []uint64{0, 1, math.MaxUint64 - 1, math.MaxUint64})
+	_, err = NewSortedSet([]uint64{math.MaxUint64}, math.MaxUint64)
+	assert.Nil(t, err)
+	_, err = NewSortedSet([]uint64{math.MaxUint64 - 1}, math.MaxUint64-1)
+	assert.NotNil(t, err)
+}
+
+func TestVerifiableParams(t *testing.T) {
+	seed := []byte("block 840000")
+
+	pp := GenVerifiable(seed, DefaultParamsDomain)
+	assert.True(t, VerifyParams(pp, seed, DefaultParamsDomain), "params should verify.")
+	assert.False(t, VerifyParams(pp, []byte("block 840001"), DefaultParamsDomain), "params should not verify.")
+	assert.False(t, VerifyParams(pp, seed, "other domain"), "params should not verify.")
+	assert.False(t, VerifyParams(Gen(), seed, DefaultParamsDomain), "random params should not verify.")
+
+	// the same seed yields the same h but fresh PRF keys
+	pp2 := GenVerifiable(seed, DefaultParamsDomain)
+	assert.True(t, pp.h.Equals(&pp2.h))
+
+	values := map[uint64]bool{1: true, 5: true, 6: true}
+	repr, com := Rep(pp, NewEnumSet(values, 8))
+	for i := uint64(0); i < 8; i++ {
+		a := Qry(pp, repr, i)
+		assert.True(t, Vfy(pp, com, i, a), "v should be true.")
+	}
+}
+
+func TestCeremony(t *testing.T) {
+	c := NewCeremony(DefaultParamsDomain)
+	_, err := c.Finalize()
+	assert.ErrorIs(t, err, ErrEmptyCeremony)
+
+	for _, name := range []string{"alice", "bob", "carol"} {
+		c.Contribute(name)
+		assert.Nil(t, VerifyCeremony(c))
+	}
+
+	// the transcript survives a round trip through its file format
+	data, err := json.Marshal(c)
+	assert.Nil(t, err)
+	var c2 Ceremony
+	assert.Nil(t, json.Unmarshal(data, &c2))
+	assert.Nil(t, VerifyCeremony(&c2))
+
+	pp, err := GenFromCeremony(&c2)
+	assert.Nil(t, err)
+	assert.True(t, VerifyCeremonyParams(pp, c))
+	assert.False(t, VerifyCeremonyParams(Gen(), c))
+
+	values := map[uint64]bool{0: true, 2: true, 9: true}
+	repr, com := Rep(pp, NewEnumSet(values, 10))
+	for i := uint64(0); i < 10; i++ {
+		a := Qry(pp, repr, i)
+		assert.True(t, Vfy(pp, com, i, a), "v should be true.")
+	}
+
+	// tampering with any contribution is detected
+	c2.Contributions[1].Participant = "mallory"
+	assert.NotNil(t, VerifyCeremony(&c2))
+	assert.Nil(t, json.Unmarshal(data, &c2))
+	c2.Contributions[2].Z.Rand()
+	assert.NotNil(t, VerifyCeremony(&c2))
+	assert.Nil(t, json.Unmarshal(data, &c2))
+	c2.Contributions[0].H.Rand()
+	assert.NotNil(t, VerifyCeremony(&c2))
+	assert.Nil(t, json.Unmarshal(data, &c2))
+	c2.Contributions = c2.Contributions[1:]
+	assert.NotNil(t, VerifyCeremony(&c2))
+}
+
+func TestSimulator(t *testing.T) {
+	for _, arity := range []uint64{2, 4} {
+		sim, err := NewSimulator(100, TreeOptions{Arity: arity})
+		assert.Nil(t, err)
+		pp, com := sim.Params(), sim.Com()
+
+		// the simulator can claim either answer for any element
+		for _, x := range []uint64{0, 1, 37, 99} {
+			a := sim.Qry(x, true)
+			assert.True(t, a.answer)
+			assert.True(t, Vfy(pp, com, x, a), "v should be true.")
+
+			b := sim.Qry(x, false)
+			assert.False(t, b.answer)
+			assert.True(t, Vfy(pp, com, x, b), "v should be true.")
+
+			assert.False(t, Vfy(pp, com, x+1, a), "v should be false.")
+		}
+	}
+}
+
+// Features a distinguisher may look at: the shape of the proof and the bit balance of its elements.
+func answerFeatures(a *Answer) (string, float64) {
+	var data []byte
+	// the parts of the answer present at each level, and the number of vector openings
+	var levels []string
+	for j := uint64(0); j <= a.levels; j++ {
+		parts := ""
+		if c, ok := a.xcoms[j]; ok {
+			parts += "x"
+			data = append(data, c.c0.Bytes()...)
+			data = append(data, c.c1.Bytes()...)
+		}
+		if c, ok := a.sibcoms[j]; ok {
+			parts += "s"
+			data = append(data, c.c0.Bytes()...)
+			data = append(data, c.c1.Bytes()...)
+		}
+		if v, ok := a.vopens[j]; ok {
+			parts += fmt.Sprint("v", len(v))
+		}
+		if o, ok := a.opens[j]; ok {
+			parts += "o"
+			data = append(data, o.r0.Bytes()...)
+			data = append(data, o.r1.Bytes()...)
+		}
+		if tau, ok := a.teases[j]; ok {
+			parts += "t"
+			data = append(data, tau.Bytes()...)
+		}
+		levels = append(levels, parts)
+	}
+
+	ones := 0
+	for _, b := range data {
+		for ; b != 0; b &= b - 1 {
+			ones++
+		}
+	}
+	shape := fmt.Sprint(a.answer, a.levels, a.arity, a.prefix, levels)
+	return shape, float64(ones) / float64(8*len(data))
+}
+
+func TestSimulatorIndistinguishable(t *testing.T) {
+	max_value := uint64(256)
+	var values = make(map[uint64]bool)
+	for i := uint64(0); i < max_value; i++ {
+		values[i] = rand.Float64() <= 0.5
+	}
+
+	pp := Gen()
+	for _, arity := range []uint64{2, 4} {
+		repr, com, err := RepWithOptions(pp, NewEnumSet(values, max_value), TreeOptions{Arity: arity})
+		assert.Nil(t, err)
+		sim, err := NewSimulator(max_value, TreeOptions{Arity: arity})
+		assert.Nil(t, err)
+
+		// simulated answers are made concurrently, as real ones are
+		n := 64
+		xs := make([]uint64, n)
+		answers := make([]*Answer, n)
+		var wg sync.WaitGroup
+		for i := range xs {
+			xs[i] = uint64(rand.Intn(int(max_value)))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				answers[i] = sim.Qry(xs[i], values[xs[i]])
+			}()
+		}
+		wg.Wait()
+
+		var real_balance, sim_balance float64
+		for i, x := range xs {
+			a, b := Qry(pp, repr, x), answers[i]
+
+			// both have the same path shape and verify, each under its own params and commitment only
+			real_shape, rb := answerFeatures(a)
+			sim_shape, sb := answerFeatures(b)
+			assert.Equal(t, real_shape, sim_shape, "simulated proofs should have the shape of real ones.")
+			assert.True(t, Vfy(pp, com, x, a), "v should be true.")
+			assert.True(t, Vfy(sim.Params(), sim.Com(), x, b), "v should be true.")
+			assert.False(t, Vfy(pp, com, x, b), "v should be false.")
+			assert.False(t, Vfy(sim.Params(), sim.Com(), x, a), "v should be false.")
+			real_balance += rb / float64(n)
+			sim_balance += sb / float64(n)
+		}
+
+		assert.InDelta(t, real_balance, sim_balance, 0.01, "simulated proofs should look like real ones.")
+	}
+}
+
+func TestJSONEncoding(t *testing.T) {
+	pp := Gen()
+
+	// prover keys and verifier params
+	key, err := pp.MarshalProverKey()
+	assert.Nil(t, err)
+	var prover PubVerPar
+	assert.Nil(t, json.Unmarshal(key, &prover))
+
+	params, err := json.Marshal(pp)
+	assert.Nil(t, err)
+	var verifier PubVerPar
+	assert.Nil(t, json.Unmarshal(params, &verifier))
+	_, err = verifier.MarshalProverKey()
+	assert.ErrorIs(t, err, ErrNoProverKey)
+	r, _ := Rep(&verifier, NewEnumSet(map[uint64]bool{1: true}, 100))
+	assert.Nil(t, r)
+
+	for _, arity := range []uint64{2, 8} {
+		values := map[uint64]bool{1: true, 20: true, 21: true}
+		repr, com, _ := RepWithOptions(pp, NewEnumSet(values, 100), TreeOptions{Arity: arity})
+
+		// a snapshot reloaded with the stored prover key commits to the same tree
+		snapshot, err := json.Marshal(repr)
+		assert.Nil(t, err)
+		repr2, com2, err := LoadRepr(&prover, snapshot)
+		assert.Nil(t, err)
+		assert.True(t, com.Equals(com2))
+		_, _, err = LoadRepr(&verifier, snapshot)
+		assert.ErrorIs(t, err, ErrNoProverKey)
+
+		// verifier params can't commit or prove
+		_, _, err = RepWithOptions(&verifier, NewEnumSet(values, 100), TreeOptions{Arity: arity})
+		assert.ErrorIs(t, err, ErrNoProverKey)
+		assert.Nil(t, Qry(&verifier, repr, 1))
+
+		data, err := json.Marshal(com)
+		assert.Nil(t, err)
+		var com3 Com
+		assert.Nil(t, json.Unmarshal(data, &com3))
+		assert.True(t, com.Equals(com3))
+
+		for _, x := range []uint64{1, 2, 21, 99} {
+			data, err := json.Marshal(Qry(&prover, repr2, x))
+			assert.Nil(t, err)
+			var a Answer
+			assert.Nil(t, json.Unmarshal(data, &a))
+			assert.Equal(t, values[x], a.Member())
+			assert.True(t, Vfy(&verifier, com3, x, &a), "v should be true.")
+		}
+	}
+
+	var a Answer
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"member":true,"levels":3,"arity":2,"xcoms":[]}`), &a), ErrMalformed)
+	_, _, err = LoadRepr(pp, []byte(`{"max":10,"arity":2,"members":[10]}`))
+	assert.NotNil(t, err)
+}
+
+func TestBinaryEncoding(t *testing.T) {
+	pp := Gen()
+
+	data, _ := pp.MarshalBinary()
+	var verifier PubVerPar
+	assert.Nil(t, verifier.UnmarshalBinary(data))
+
+	for _, arity := range []uint64{2, 4} {
+		values := map[uint64]bool{0: true, 9: true}
+		repr, com, _ := RepWithOptions(pp, NewEnumSet(values, 50), TreeOptions{Arity: arity})
+
+		data, _ := com.MarshalBinary()
+		var com2 Com
+		assert.Nil(t, com2.UnmarshalBinary(data))
+		assert.True(t, com.Equals(com2))
+
+		for _, x := range []uint64{0, 1, 9, 49} {
+			data, _ := Qry(pp, repr, x).MarshalBinary()
+			var a Answer
+			assert.Nil(t, a.UnmarshalBinary(data))
+			assert.True(t, Vfy(&verifier, com2, x, &a), "v should be true.")
+
+			// truncated or extended encodings are rejected
+			assert.ErrorIs(t, a.UnmarshalBinary(data[:len(data)-1]), ErrMalformed)
+			assert.ErrorIs(t, a.UnmarshalBinary(append(data, 0)), ErrMalformed)
+		}
+	}
+}
+
+// Size in bytes of the proof carried by an answer (points and scalars are 32 bytes).
+func proofSize(a *Answer) int {
+	size := 64 * (len(a.xcoms) + len(a.sibcoms) + len(a.opens))
+	size += 32 * len(a.teases)
+	for _, opening := range a.vopens {
+		size += 32 * len(opening)
+	}
+	return size
+}
+
+func benchmarkRepr(b *testing.B, arity uint64) (*PubVerPar, *Repr, Com, []uint64) {
+	u := uint64(1 << 12)
+	var values = make(map[uint64]bool)
+	for i := uint64(0); i < u; i++ {
+		values[i] = rand.Float64() <= 0.1
+	}
+
+	pp := Gen()
+	repr, com, err := RepWithOptions(pp, NewEnumSet(values, u), TreeOptions{Arity: arity})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	xs := make([]uint64, 64)
+	for i := range xs {
+		xs[i] = uint64(rand.Intn(int(u)))
+	}
+	return pp, repr, com, xs
+}
+
+func BenchmarkQryArity(b *testing.B) {
+	for _, arity := range []uint64{2, 4, 8, 16} {
+		b.Run(fmt.Sprintf("arity=%d", arity), func(b *testing.B) {
+			pp, repr, _, xs := benchmarkRepr(b, arity)
+			size := 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				size += proofSize(Qry(pp, repr, xs[i%len(xs)]))
+			}
+			b.ReportMetric(float64(size)/float64(b.N), "proof-bytes")
+		})
+	}
+}
+
+func BenchmarkVfyArity(b *testing.B) {
+	for _, arity := range []uint64{2, 4, 8, 16} {
+		b.Run(fmt.Sprintf("arity=%d", arity), func(b *testing.B) {
+			pp, repr, com, xs := benchmarkRepr(b, arity)
+			answers := make([]*Answer, len(xs))
+			for i, x := range xs {
+				answers[i] = Qry(pp, repr, x)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				Vfy(pp, com, xs[i%len(xs)], answers[i%len(xs)])
+			}
+		})
+	}
+}
+
 type WAgg struct {
 	count int
 	mean  float64
@@ -523,3 +1080,1064 @@ func TestPerformance(t *testing.T) {
 		}
 	}
 }
+
+func TestGenFromSeed(t *testing.T) {
+	values := map[uint64]bool{1: true, 5: true, 6: true}
+	_, com := Rep(GenFromSeed([]byte("seed")), NewEnumSet(values, 8))
+	_, com2 := Rep(GenFromSeed([]byte("seed")), NewEnumSet(values, 8))
+	_, com3 := Rep(GenFromSeed([]byte("other seed")), NewEnumSet(values, 8))
+	assert.True(t, com.Equals(com2), "the same seed should yield the same commitment.")
+	assert.False(t, com.Equals(com3), "another seed should yield another commitment.")
+	assert.True(t, VerifyParams(GenFromSeed([]byte("seed")), []byte("seed"), DefaultParamsDomain))
+}
+
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("testdata/vectors.json")
+	assert.Nil(t, err)
+	var vectors []*TestVector
+	assert.Nil(t, json.Unmarshal(data, &vectors))
+	assert.NotEmpty(t, vectors)
+
+	for i, v := range vectors {
+		assert.Nil(t, v.Check(), "vector %d", i)
+	}
+
+	// a vector under another seed is not reproduced
+	v := vectors[len(vectors)-1]
+	v.Seed = []byte("other seed")
+	assert.NotNil(t, v.Check())
+}
+
+func TestSignedCommitment(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	values := map[uint64]bool{1: true, 5: true, 6: true}
+	pp := Gen()
+	repr, com := Rep(pp, NewEnumSet(values, 8))
+
+	sc := Sign(priv, repr, 7, time.Now())
+	assert.Nil(t, VerifySigned(pub, sc))
+	assert.True(t, com.Equals(sc.Com))
+	assert.Equal(t, uint64(3), sc.Depth)
+	assert.Equal(t, uint64(2), sc.Arity)
+	for i := uint64(0); i < 8; i++ {
+		assert.True(t, VfySigned(pp, pub, sc, i, Qry(pp, repr, i)), "v should be true.")
+	}
+
+	// the signature survives encoding
+	data, err := json.Marshal(sc)
+	assert.Nil(t, err)
+	var sc2 SignedCommitment
+	assert.Nil(t, json.Unmarshal(data, &sc2))
+	assert.Nil(t, VerifySigned(pub, &sc2))
+
+	// other keys, altered fields and other schemes are rejected
+	other, _, _ := ed25519.GenerateKey(nil)
+	assert.ErrorIs(t, VerifySigned(other, sc), ErrBadSignature)
+	assert.False(t, VfySigned(pp, other, sc, 1, Qry(pp, repr, 1)))
+	sc2.Epoch++
+	assert.ErrorIs(t, VerifySigned(pub, &sc2), ErrBadSignature)
+	sc2.Epoch--
+	sc2.Arity = 4
+	assert.ErrorIs(t, VerifySigned(pub, &sc2), ErrBadSignature)
+	sc2.Arity = 2
+	sc2.Timestamp = sc2.Timestamp.Add(time.Second)
+	assert.ErrorIs(t, VerifySigned(pub, &sc2), ErrBadSignature)
+	sc2.Timestamp = sc.Timestamp
+	sc2.Scheme = "zks-v0"
+	assert.ErrorIs(t, VerifySigned(pub, &sc2), ErrUnknownScheme)
+
+	// answers from a tree of another depth or arity than the signed one are rejected
+	for _, shape := range [][2]uint64{{4, 2}, {3, 4}} {
+		sc3 := &SignedCommitment{Com: com, Depth: shape[0], Arity: shape[1], Epoch: 8, Timestamp: time.Now(), Scheme: SchemeID}
+		sc3.Signature = ed25519.Sign(priv, sc3.message())
+		assert.Nil(t, VerifySigned(pub, sc3))
+		assert.True(t, Vfy(pp, sc3.Com, 1, Qry(pp, repr, 1)))
+		assert.False(t, VfySigned(pp, pub, sc3, 1, Qry(pp, repr, 1)))
+	}
+}
+
+func TestEquivocation(t *testing.T) {
+	// a prover knowing the trapdoor of h can answer both ways
+	sim, err := NewSimulator(16, TreeOptions{})
+	assert.Nil(t, err)
+	pp, com := sim.Params(), sim.Com()
+	yes, no := sim.Qry(3, true), sim.Qry(3, false)
+
+	ev := DetectEquivocation(pp, com, 3, yes, no)
+	assert.NotNil(t, ev)
+	assert.Nil(t, VerifyEvidence(pp, nil, ev))
+
+	// the evidence survives encoding
+	data, err := json.Marshal(ev)
+	assert.Nil(t, err)
+	var ev2 Evidence
+	assert.Nil(t, json.Unmarshal(data, &ev2))
+	assert.Nil(t, VerifyEvidence(pp, nil, &ev2))
+
+	// identical answers, answers that don't verify and other parameters are no evidence
+	assert.Nil(t, DetectEquivocation(pp, com, 3, yes, yes))
+	assert.Nil(t, DetectEquivocation(pp, com, 4, yes, no))
+	assert.ErrorIs(t, VerifyEvidence(pp, nil, &Evidence{nil, com, 3, [2]*Answer{no, no}}), ErrNoEquivocation)
+	assert.NotNil(t, VerifyEvidence(Gen(), nil, ev))
+
+	// an honest prover never equivocates
+	hpp := Gen()
+	repr, hcom := Rep(hpp, NewEnumSet(map[uint64]bool{3: true}, 16))
+	assert.Nil(t, DetectEquivocation(hpp, hcom, 3, Qry(hpp, repr, 3), Qry(hpp, repr, 3)))
+
+	// signed evidence names the prover
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	sc := &SignedCommitment{Com: com, Depth: 4, Arity: 2, Epoch: 1, Timestamp: time.Now(), Scheme: SchemeID}
+	sc.Signature = ed25519.Sign(priv, sc.message())
+	ev = DetectSignedEquivocation(pp, pub, sc, 3, yes, no)
+	assert.NotNil(t, ev)
+	assert.Nil(t, VerifyEvidence(pp, pub, ev))
+	other, _, _ := ed25519.GenerateKey(nil)
+	assert.ErrorIs(t, VerifyEvidence(pp, other, ev), ErrBadSignature)
+	assert.Nil(t, DetectSignedEquivocation(pp, other, sc, 3, yes, no))
+}
+
+func TestCrossCheck(t *testing.T) {
+	sim, err := NewSimulator(16, TreeOptions{Arity: 4})
+	assert.Nil(t, err)
+	pp, com := sim.Params(), sim.Com()
+
+	// two verifiers collect answers; the prover lies to the second one about 5 and 9
+	var obs []Observation
+	for x := uint64(0); x < 16; x++ {
+		obs = append(obs, Observation{x, sim.Qry(x, x%2 == 1)})
+	}
+	for _, x := range []uint64{1, 2, 5, 9} {
+		obs = append(obs, Observation{x, sim.Qry(x, (x%2 == 1) != (x > 4))})
+	}
+	obs = append(obs, Observation{7, sim.Qry(8, false)})
+
+	evidence := CrossCheck(pp, com, obs)
+	assert.Len(t, evidence, 2)
+	for i, x := range []uint64{5, 9} {
+		assert.Equal(t, x, evidence[i].X)
+		assert.Nil(t, VerifyEvidence(pp, nil, evidence[i]))
+	}
+}
+
+func TestFreshAnswers(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	pp := Gen()
+	repr, _ := Rep(pp, NewEnumSet(map[uint64]bool{1: true, 5: true}, 8))
+	sc := Sign(priv, repr, 1, time.Now())
+
+	nonce := NewNonce()
+	for x := uint64(0); x < 8; x++ {
+		fa := QryFresh(pp, repr, priv, sc, x, nonce)
+		assert.True(t, VfyFresh(pp, pub, x, fa, FreshOptions{MinEpoch: 1, Nonce: nonce}), "v should be true.")
+		assert.False(t, VfyFresh(pp, pub, x^1, fa, FreshOptions{}), "v should be false.")
+	}
+	assert.Nil(t, QryFresh(pp, repr, priv, sc, 8, nonce))
+
+	// the answer survives encoding
+	fa := QryFresh(pp, repr, priv, sc, 5, nonce)
+	data, err := json.Marshal(fa)
+	assert.Nil(t, err)
+	var fa2 FreshAnswer
+	assert.Nil(t, json.Unmarshal(data, &fa2))
+	assert.True(t, VfyFresh(pp, pub, 5, &fa2, FreshOptions{MinEpoch: 1, Nonce: nonce}))
+
+	// a replayed answer carries another nonce
+	assert.False(t, VfyFresh(pp, pub, 5, fa, FreshOptions{Nonce: NewNonce()}))
+	fa2.Nonce = NewNonce()
+	assert.False(t, VfyFresh(pp, pub, 5, &fa2, FreshOptions{Nonce: fa2.Nonce}))
+
+	// once the set changes, answers from the old epoch are stale
+	repr2, _ := Rep(pp, NewEnumSet(map[uint64]bool{1: true}, 8))
+	sc2 := Sign(priv, repr2, 2, time.Now())
+	assert.False(t, VfyFresh(pp, pub, 5, fa, FreshOptions{MinEpoch: 2}))
+	assert.True(t, VfyFresh(pp, pub, 5, QryFresh(pp, repr2, priv, sc2, 5, nonce), FreshOptions{MinEpoch: 2, Nonce: nonce}))
+
+	// only the prover can bind an answer to a nonce
+	_, other, _ := ed25519.GenerateKey(nil)
+	forged := QryFresh(pp, repr2, other, sc2, 5, nonce)
+	assert.False(t, VfyFresh(pp, pub, 5, forged, FreshOptions{MinEpoch: 2, Nonce: nonce}))
+}
+
+func TestOfflineBundle(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	pp := Gen()
+	repr, _ := Rep(pp, NewEnumSet(map[uint64]bool{1: true, 5: true}, 8))
+	sc := Sign(priv, repr, 1, time.Now())
+	now := time.Now()
+	expires := now.Add(24 * time.Hour)
+
+	b, err := NewBundle(pp, repr, priv, sc, []uint64{1, 2, 5, 7}, expires)
+	assert.Nil(t, err)
+
+	// the bundle is self-contained and survives encoding
+	data, err := json.Marshal(b)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "prf")
+	decode := func() *Bundle {
+		var b Bundle
+		assert.Nil(t, json.Unmarshal(data, &b))
+		return &b
+	}
+	trusted := &PubVerPar{h: pp.h}
+	members, err := VerifyBundle(pub, decode(), BundleOptions{Params: trusted, Now: now})
+	assert.Nil(t, err)
+	assert.Equal(t, map[uint64]bool{1: true, 2: false, 5: true, 7: false}, members)
+
+	// bundles are only verified under trusted params
+	_, err = VerifyBundle(pub, decode(), BundleOptions{Now: now})
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+
+	// expired bundles are rejected, and the expiry can't be extended
+	_, err = VerifyBundle(pub, decode(), BundleOptions{Params: trusted, Now: expires})
+	assert.ErrorIs(t, err, ErrBundleExpired)
+	tampered := decode()
+	tampered.Expires = expires.Add(time.Hour)
+	_, err = VerifyBundle(pub, tampered, BundleOptions{Params: trusted, Now: expires})
+	assert.ErrorIs(t, err, ErrBadSignature)
+
+	// nor can answers be dropped, swapped or moved to other elements
+	tampered = decode()
+	tampered.Entries = tampered.Entries[1:]
+	_, err = VerifyBundle(pub, tampered, BundleOptions{Params: trusted, Now: now})
+	assert.ErrorIs(t, err, ErrBadSignature)
+	tampered = decode()
+	tampered.Entries[0].Answer = tampered.Entries[1].Answer
+	_, err = VerifyBundle(pub, tampered, BundleOptions{Params: trusted, Now: now})
+	assert.ErrorIs(t, err, ErrBadSignature)
+	tampered = decode()
+	tampered.Entries[0].Answer = nil
+	_, err = VerifyBundle(pub, tampered, BundleOptions{Params: trusted, Now: now})
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+
+	// the bundle must be signed by the prover and hold the trusted params
+	otherPub, otherPriv, _ := ed25519.GenerateKey(nil)
+	_, err = VerifyBundle(otherPub, decode(), BundleOptions{Params: trusted, Now: now})
+	assert.ErrorIs(t, err, ErrBadSignature)
+	_, err = VerifyBundle(pub, decode(), BundleOptions{Params: Gen(), Now: now})
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+
+	forged, _ := NewBundle(pp, repr, otherPriv, sc, []uint64{1}, expires)
+	_, err = VerifyBundle(pub, forged, BundleOptions{Params: trusted, Now: now})
+	assert.ErrorIs(t, err, ErrBadSignature)
+	_, err = VerifyBundle(pub, nil, BundleOptions{Params: trusted, Now: now})
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+
+	// a prover knowing the trapdoor of the params it bundles could answer anything
+	sim, _ := NewSimulator(8, TreeOptions{})
+	simSigned := &SignedCommitment{Com: sim.Com(), Depth: 3, Arity: 2, Epoch: 1, Timestamp: now, Scheme: SchemeID}
+	simSigned.Signature = ed25519.Sign(priv, simSigned.message())
+	lie := &Bundle{simSigned, &PubVerPar{h: sim.Params().h}, []BundleEntry{{2, sim.Qry(2, true)}}, expires, nil}
+	lie.Signature = ed25519.Sign(priv, lie.message())
+	_, err = VerifyBundle(pub, lie, BundleOptions{Params: &PubVerPar{h: sim.Params().h}, Now: now})
+	assert.Nil(t, err)
+	_, err = VerifyBundle(pub, lie, BundleOptions{Params: trusted, Now: now})
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+	_, err = VerifyBundle(pub, lie, BundleOptions{Now: now})
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+
+	// bundles are only made for the signed representation and elements in the universe
+	repr2, _ := Rep(pp, NewEnumSet(map[uint64]bool{1: true}, 8))
+	_, err = NewBundle(pp, repr2, priv, sc, []uint64{1}, expires)
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+	_, err = NewBundle(pp, repr2, priv, Sign(priv, repr2, 2, now), []uint64{8}, expires)
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+	_, err = NewBundle(trusted, repr, priv, sc, []uint64{1}, expires)
+	assert.ErrorIs(t, err, ErrNoProverKey)
+}
+
+func TestCommitLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coms.log")
+	log, err := OpenLog(path)
+	assert.Nil(t, err)
+
+	// every commitment computed under parameters holding the log is appended once
+	pp := Gen()
+	logged := pp.WithLog(log)
+	var coms []Com
+	appended := func(com Com) {
+		coms = append(coms, com)
+		assert.Equal(t, uint64(len(coms)), log.Size())
+		latest, err := log.Entry(log.Size() - 1)
+		assert.Nil(t, err)
+		assert.True(t, latest.Equals(com))
+	}
+	var repr *Repr
+	for i := uint64(0); i < 8; i++ {
+		r, com, err := RepWithOptions(logged, NewEnumSet(map[uint64]bool{i: true}, 16), TreeOptions{})
+		assert.Nil(t, err)
+		appended(com)
+		repr = r
+	}
+
+	// committing to the latest commitment again, e.g. reloaded from a snapshot, doesn't grow the log
+	_, com := Rep(logged, NewEnumSet(map[uint64]bool{7: true}, 16))
+	assert.True(t, com.Equals(coms[7]))
+	snapshot, err := json.Marshal(repr)
+	assert.Nil(t, err)
+	_, _, err = LoadRepr(logged, snapshot)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(8), log.Size())
+
+	// as are grown representations, forests (only their top-level commitment) and the sets of tenants
+	grown, com, _, err := repr.GrowUniverse(logged, 1000)
+	assert.Nil(t, err)
+	appended(com)
+	snapshot, err = json.Marshal(grown)
+	assert.Nil(t, err)
+	_, _, err = LoadRepr(logged, snapshot)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(9), log.Size())
+	_, com = Rep(logged, NewEnumSet(map[uint64]bool{8: true, 9: true}, 16))
+	appended(com)
+	_, com, err = NewForest(logged, map[string]MembershipSource{"a": NewEnumSet(map[uint64]bool{1: true}, 16)}, TreeOptions{})
+	assert.Nil(t, err)
+	appended(com)
+	r := NewRegistry(GenMaster().WithLog(log))
+	for i := 0; i < 2; i++ {
+		com, err = r.Publish("tenant", NewEnumSet(map[uint64]bool{3: true}, 16), TreeOptions{})
+		assert.Nil(t, err)
+	}
+	appended(com)
+
+	// parameters without the log don't publish to it
+	_, _, err = RepWithOptions(pp, NewEnumSet(map[uint64]bool{10: true}, 16), TreeOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(12), log.Size())
+	assert.Nil(t, log.Close())
+
+	// nor is a commitment that can't be logged returned
+	_, _, err = RepWithOptions(logged, NewEnumSet(map[uint64]bool{11: true}, 16), TreeOptions{})
+	assert.NotNil(t, err)
+	failed, _ := Rep(logged, NewEnumSet(map[uint64]bool{12: true}, 16))
+	assert.Nil(t, failed)
+
+	// the log is read back from disk
+	log, err = OpenLog(path)
+	assert.Nil(t, err)
+	defer log.Close()
+	assert.Equal(t, uint64(12), log.Size())
+
+	for size := uint64(1); size <= 12; size++ {
+		root, err := log.Root(size)
+		assert.Nil(t, err)
+		for i := uint64(0); i < size; i++ {
+			entry, err := log.Entry(i)
+			assert.Nil(t, err)
+			assert.True(t, entry.Equals(coms[i]))
+
+			proof, err := log.InclusionProof(i, size)
+			assert.Nil(t, err)
+			assert.True(t, VerifyInclusion(coms[i], i, size, proof, root), "entry %d of %d", i, size)
+			assert.False(t, VerifyInclusion(coms[(i+1)%12], i, size, proof, root), "entry %d of %d", i, size)
+		}
+
+		for old := uint64(0); old <= size; old++ {
+			oldRoot, _ := log.Root(old)
+			proof, err := log.ConsistencyProof(old, size)
+			assert.Nil(t, err)
+			assert.True(t, VerifyConsistency(old, size, oldRoot, root, proof), "%d to %d", old, size)
+			if old > 0 && old < size {
+				other, _ := log.Root(old - 1)
+				assert.False(t, VerifyConsistency(old, size, other, root, proof), "%d to %d", old, size)
+			}
+		}
+	}
+
+	_, err = log.InclusionProof(12, 12)
+	assert.ErrorIs(t, err, ErrLogRange)
+	_, err = log.Root(13)
+	assert.ErrorIs(t, err, ErrLogRange)
+
+	// a torn write is detected
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.Write([]byte{1, 2, 3})
+	f.Close()
+	_, err = OpenLog(path)
+	assert.NotNil(t, err)
+}
+
+func TestAudit(t *testing.T) {
+	pp := Gen()
+	values := map[uint64]bool{0: true, 3: true, 9: true, 14: true, 15: true}
+	for _, opts := range []TreeOptions{{}, {Arity: 4}, {Arity: 16}, {Depth: 8}} {
+		repr, com, err := RepWithOptions(pp, NewEnumSet(values, 16), opts)
+		assert.Nil(t, err)
+		b := repr.OpenAll()
+		assert.Equal(t, []uint64{0, 3, 9, 14, 15}, b.Members)
+		assert.Nil(t, VerifyAudit(pp, com, b), "options %v", opts)
+
+		// the bundle survives encoding
+		data, err := json.Marshal(b)
+		assert.Nil(t, err)
+		var b2 AuditBundle
+		assert.Nil(t, json.Unmarshal(data, &b2))
+		assert.Nil(t, VerifyAudit(pp, com, &b2))
+
+		// hiding a member, claiming another one or opening another commitment is detected
+		b2.Members = []uint64{0, 3, 9, 14}
+		assert.NotNil(t, VerifyAudit(pp, com, &b2))
+		b2.Members = []uint64{0, 3, 9, 14, 15, 1}
+		assert.NotNil(t, VerifyAudit(pp, com, &b2))
+		_, other := Rep(pp, NewEnumSet(map[uint64]bool{0: true}, 16))
+		assert.NotNil(t, VerifyAudit(pp, other, b))
+	}
+
+	// a soft node can't be passed off as a hard one
+	repr, com := Rep(pp, NewEnumSet(values, 16))
+	b := repr.OpenAll()
+	for i := range b.Nodes {
+		if b.Nodes[i].Soft {
+			b.Nodes[i].Soft = false
+			break
+		}
+	}
+	assert.NotNil(t, VerifyAudit(pp, com, b))
+
+	// degenerate universes
+	for _, es := range []*EnumSet{NewEnumSet(map[uint64]bool{}, 0), NewEnumSet(map[uint64]bool{0: true}, 1), NewEnumSet(map[uint64]bool{}, 1)} {
+		repr, com := Rep(pp, es)
+		assert.Nil(t, VerifyAudit(pp, com, repr.OpenAll()))
+	}
+}
+
+func TestGrowUniverse(t *testing.T) {
+	pp := Gen()
+	values := map[uint64]bool{0: true, 3: true, 9: true, 14: true, 15: true}
+	for _, opts := range []TreeOptions{{}, {Arity: 4}} {
+		repr, com, err := RepWithOptions(pp, NewEnumSet(values, 16), opts)
+		assert.Nil(t, err)
+
+		// grow twice, keeping every answer of the original tree
+		repr2, com2, proof, err := repr.GrowUniverse(pp, 100)
+		assert.Nil(t, err)
+		assert.True(t, VerifyGrowth(pp, com, com2, proof))
+		repr3, com3, proof2, err := repr2.GrowUniverse(pp, 5000)
+		assert.Nil(t, err)
+		assert.True(t, VerifyGrowth(pp, com2, com3, proof2))
+		assert.False(t, VerifyGrowth(pp, com, com3, proof2))
+
+		// the proof survives encoding
+		data, err := json.Marshal(proof)
+		assert.Nil(t, err)
+		var decodedProof GrowthProof
+		assert.Nil(t, json.Unmarshal(data, &decodedProof))
+		assert.True(t, VerifyGrowth(pp, com, com2, &decodedProof))
+		assert.False(t, VerifyGrowth(pp, com2, com3, proof))
+
+		for _, x := range []uint64{0, 1, 3, 9, 10, 15} {
+			a := Qry(pp, repr, x)
+			extended := proof2.Extend(proof.Extend(a))
+			assert.True(t, Vfy(pp, com3, x, extended), "options %v x %d", opts, x)
+			assert.Equal(t, values[x], extended.Member())
+
+			// the grown tree answers the same
+			a3 := Qry(pp, repr3, x)
+			assert.True(t, Vfy(pp, com3, x, a3))
+			data, _ := json.Marshal(a3)
+			data2, _ := json.Marshal(extended)
+			assert.Equal(t, string(data), string(data2))
+		}
+		checkQueries(t, pp, repr3, com3, NewEnumSet(values, 5000), []uint64{16, 17, 99, 100, 255, 256, 1000, 4999})
+
+		// an extended member answer verifies for no other element
+		a := proof.Extend(Qry(pp, repr, 3))
+		assert.False(t, Vfy(pp, com2, 19, a))
+		assert.False(t, Vfy(pp, com, 3, a))
+
+		// snapshots, binary encodings and audits of the grown tree
+		data, err = json.Marshal(repr3)
+		assert.Nil(t, err)
+		repr4, com4, err := LoadRepr(pp, data)
+		assert.Nil(t, err)
+		assert.True(t, com4.Equals(com3))
+		assert.Equal(t, repr3.tree.members(), repr4.tree.members())
+		assert.Nil(t, VerifyAudit(pp, com3, repr3.OpenAll()))
+
+		bin, err := Qry(pp, repr3, 9).MarshalBinary()
+		assert.Nil(t, err)
+		var decoded Answer
+		assert.Nil(t, decoded.UnmarshalBinary(bin))
+		assert.True(t, Vfy(pp, com3, 9, &decoded))
+	}
+
+	// a bound within the depth only raises the bound, shrinking fails
+	repr, com := Rep(pp, NewEnumSet(values, 16))
+	_, com2, proof, err := repr.GrowUniverse(pp, 16)
+	assert.Nil(t, err)
+	assert.True(t, com2.Equals(com))
+	assert.Equal(t, uint64(0), proof.Levels())
+	assert.True(t, VerifyGrowth(pp, com, com2, proof))
+	_, _, _, err = repr.GrowUniverse(pp, 8)
+	assert.ErrorIs(t, err, ErrUniverseShrinks)
+
+	// so do sets without a path to element 0 in their tree
+	for _, opts := range []TreeOptions{{}, {Arity: 4}} {
+		sparse := map[uint64]bool{10: true}
+		repr, com, _ := RepWithOptions(pp, NewEnumSet(sparse, 16), opts)
+		repr2, com2, proof, err := repr.GrowUniverse(pp, 1000)
+		assert.Nil(t, err)
+		assert.True(t, VerifyGrowth(pp, com, com2, proof))
+		for _, x := range []uint64{0, 10, 11} {
+			assert.True(t, Vfy(pp, com2, x, proof.Extend(Qry(pp, repr, x))), "options %v x %d", opts, x)
+			assert.True(t, Vfy(pp, com2, x, Qry(pp, repr2, x)), "options %v x %d", opts, x)
+		}
+	}
+
+	// degenerate universes grow too
+	for _, es := range []*EnumSet{NewEnumSet(map[uint64]bool{}, 0), NewEnumSet(map[uint64]bool{0: true}, 1)} {
+		repr, com := Rep(pp, es)
+		repr2, com2, proof, err := repr.GrowUniverse(pp, 8)
+		assert.Nil(t, err)
+		assert.True(t, VerifyGrowth(pp, com, com2, proof))
+		checkQueries(t, pp, repr2, com2, NewEnumSet(es.set, 8), []uint64{0, 1, 7})
+		assert.True(t, Vfy(pp, com2, 0, proof.Extend(Qry(pp, repr, 0))))
+	}
+}
+
+func TestForest(t *testing.T) {
+	pp := Gen()
+	values := map[string]map[uint64]bool{
+		"admins":  {1: true, 5: true},
+		"users":   {1: true, 2: true, 3: true, 5: true, 8: true},
+		"banned":  {},
+		"mirrors": {1: true, 5: true},
+	}
+	sets := make(map[string]MembershipSource)
+	for name, vs := range values {
+		sets[name] = NewEnumSet(vs, 16)
+	}
+	for _, opts := range []TreeOptions{{}, {Arity: 4}} {
+		forest, com, err := NewForest(pp, sets, opts)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"admins", "banned", "mirrors", "users"}, forest.Names())
+
+		for _, name := range []string{"admins", "users", "banned", "mirrors", "guests"} {
+			for x := uint64(0); x < 16; x++ {
+				a := forest.Qry(name, x)
+				assert.True(t, VfyForest(pp, com, name, x, a), "options %v set %s x %d", opts, name, x)
+				assert.Equal(t, values[name][x], a.Member(), "set %s x %d", name, x)
+				assert.Equal(t, name != "guests", a.HasSet())
+			}
+		}
+
+		// an answer holds for its set and element only
+		a := forest.Qry("admins", 5)
+		assert.False(t, VfyForest(pp, com, "mirrors", 5, a))
+		assert.False(t, VfyForest(pp, com, "admins", 4, a))
+		absent := forest.Qry("guests", 5)
+		assert.False(t, VfyForest(pp, com, "admins", 5, absent))
+
+		// nor can the set be swapped for another one of the forest
+		_, mirrors, _ := forest.Set("mirrors")
+		swapped := *a
+		swapped.com = mirrors
+		assert.False(t, VfyForest(pp, com, "admins", 5, &swapped))
+
+		// the answer survives encoding
+		for name, a := range map[string]*ForestAnswer{"admins": a, "guests": absent} {
+			data, err := json.Marshal(a)
+			assert.Nil(t, err)
+			var decoded ForestAnswer
+			assert.Nil(t, json.Unmarshal(data, &decoded))
+			assert.True(t, VfyForest(pp, com, name, 5, &decoded))
+		}
+		assert.NotNil(t, json.Unmarshal([]byte(`{"set":true,"path":{}}`), new(ForestAnswer)))
+
+		// sets with equal members are committed independently
+		_, admins, _ := forest.Set("admins")
+		assert.False(t, mirrors.Equals(admins))
+	}
+
+	// invalid sets and verifier params are rejected
+	_, _, err := NewForest(pp, map[string]MembershipSource{"bad": NewEnumSet(nil, 16)}, TreeOptions{Arity: 3})
+	assert.ErrorIs(t, err, ErrInvalidArity)
+	var vpp PubVerPar
+	data, _ := json.Marshal(pp)
+	json.Unmarshal(data, &vpp)
+	_, _, err = NewForest(&vpp, sets, TreeOptions{})
+	assert.ErrorIs(t, err, ErrNoProverKey)
+}
+
+func TestTenants(t *testing.T) {
+	master := GenMaster()
+	vpp := master.VerifierParams()
+	values := map[uint64]bool{1: true, 5: true, 9: true}
+
+	r := NewRegistry(master)
+	comA, err := r.Publish("tenant-a", NewEnumSet(values, 16), TreeOptions{})
+	assert.Nil(t, err)
+	comB, err := r.Publish("tenant-b", NewEnumSet(values, 16), TreeOptions{Arity: 4})
+	assert.Nil(t, err)
+	_, err = r.Publish("tenant-c", NewEnumSet(values, 16), TreeOptions{Arity: 3})
+	assert.ErrorIs(t, err, ErrInvalidArity)
+	assert.Equal(t, []string{"tenant-a", "tenant-b"}, r.Tenants())
+
+	for x := uint64(0); x < 16; x++ {
+		a, err := r.Qry("tenant-a", x)
+		assert.Nil(t, err)
+		assert.True(t, Vfy(vpp, comA, x, a), "answer for %d", x)
+		assert.Equal(t, values[x], a.Member())
+		assert.False(t, Vfy(vpp, comB, x, a), "answer of one tenant verifies for another")
+	}
+	_, err = r.Qry("tenant-c", 1)
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+
+	// the same set is committed independently for every tenant, and deterministically for each
+	comA2, _ := r.Publish("tenant-a2", NewEnumSet(values, 16), TreeOptions{})
+	assert.False(t, comA.Equals(comA2))
+	_, comA3 := Rep(master.Child("tenant-a").Params(), NewEnumSet(values, 16))
+	assert.True(t, comA.Equals(comA3))
+
+	// children of children are keyed under their path, and the master key survives encoding
+	data, err := json.Marshal(master)
+	assert.Nil(t, err)
+	var decoded MasterKey
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	_, com1 := Rep(master.Child("org").Child("team").Params(), NewEnumSet(values, 16))
+	_, com2 := Rep(decoded.Child("org").Child("team").Params(), NewEnumSet(values, 16))
+	_, com3 := Rep(master.Child("orgteam").Params(), NewEnumSet(values, 16))
+	assert.True(t, com1.Equals(com2))
+	assert.False(t, com1.Equals(com3))
+	assert.NotNil(t, json.Unmarshal([]byte(`{"h":"`+strings.Repeat("A", 43)+`"}`), &decoded))
+	short, _ := json.Marshal(&masterKeyJSON{master.h, master.secret[:16]})
+	assert.ErrorIs(t, json.Unmarshal(short, &decoded), ErrMalformed)
+
+	// a removed tenant is no longer served
+	r.Remove("tenant-a")
+	_, err = r.Qry("tenant-a", 1)
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+	_, ok := r.Commitment("tenant-a")
+	assert.False(t, ok)
+	com, ok := r.Commitment("tenant-b")
+	assert.True(t, ok)
+	assert.True(t, com.Equals(comB))
+}
+
+func TestConcurrentPublishes(t *testing.T) {
+	log, err := OpenLog(filepath.Join(t.TempDir(), "coms.log"))
+	assert.Nil(t, err)
+	defer log.Close()
+	r := NewRegistry(GenMaster().WithLog(log))
+
+	// publishes of a tenant take turns, so the commitment served is the one logged last,
+	// and a failed first publish leaves no tenant behind
+	var wg sync.WaitGroup
+	for i := uint64(0); i < 8; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			_, err := r.Publish("tenant", NewEnumSet(map[uint64]bool{i: true}, 16), TreeOptions{})
+			assert.Nil(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := r.Publish("invalid", NewEnumSet(map[uint64]bool{i: true}, 16), TreeOptions{Arity: 3})
+			assert.ErrorIs(t, err, ErrInvalidArity)
+		}()
+		// readers run alongside
+		go func() {
+			defer wg.Done()
+			r.Qry("tenant", i)
+			r.Commitment("tenant")
+			r.Tenants()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(8), log.Size())
+	latest, err := log.Entry(7)
+	assert.Nil(t, err)
+	com, ok := r.Commitment("tenant")
+	assert.True(t, ok)
+	assert.True(t, com.Equals(latest))
+	assert.Equal(t, []string{"tenant"}, r.Tenants())
+	_, err = r.Qry("invalid", 1)
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+}
+
+func TestProofCache(t *testing.T) {
+	pp := Gen()
+	values := map[uint64]bool{0: true, 3: true, 9: true, 14: true, 15: true}
+	for _, opts := range []TreeOptions{{}, {Arity: 4}} {
+		repr, com, err := RepWithOptions(pp, NewEnumSet(values, 16), opts)
+		assert.Nil(t, err)
+		nonMember, _ := Qry(pp, repr, 1).MarshalBinary()
+
+		// room for two non-member answers
+		c, err := Precompute(pp, repr, CacheOptions{MaxNonMemberBytes: 2 * len(nonMember)})
+		assert.Nil(t, err)
+		stats := c.Stats()
+		assert.Equal(t, 5, stats.Members)
+		assert.Positive(t, stats.MemberBytes)
+		assert.Equal(t, 0, stats.NonMembers)
+
+		// cached answers are the answers of Qry
+		for x := uint64(0); x < 16; x++ {
+			want, _ := Qry(pp, repr, x).MarshalBinary()
+			assert.Equal(t, want, c.QryBinary(x), "answer for %d", x)
+			a := c.Qry(x)
+			assert.True(t, Vfy(pp, com, x, a), "answer for %d", x)
+			assert.Equal(t, values[x], a.Member())
+		}
+		assert.Nil(t, c.Qry(16))
+
+		// 11 non-members were queried, only 2 are kept
+		stats = c.Stats()
+		assert.Equal(t, 2, stats.NonMembers)
+		assert.LessOrEqual(t, stats.NonMemberBytes, 2*len(nonMember))
+		assert.Equal(t, uint64(9), stats.Evictions)
+		assert.Equal(t, uint64(12), stats.Misses)
+		assert.Equal(t, uint64(21), stats.Hits)
+
+		// warmup computes the non-members not cached yet
+		assert.Equal(t, 2, c.Warmup(slices.Values([]uint64{0, 1, 2, 15, 16})))
+		hits := c.Stats().Hits
+		c.QryBinary(1)
+		c.QryBinary(2)
+		assert.Equal(t, hits+2, c.Stats().Hits)
+
+		// callers can't alter the cached answers
+		for _, x := range []uint64{1, 3} {
+			data := c.QryBinary(x)
+			clear(data)
+			assert.True(t, Vfy(pp, com, x, c.Qry(x)), "answer for %d", x)
+		}
+	}
+
+	// a cache without room for non-members computes them every time
+	repr, com := Rep(pp, NewEnumSet(values, 16))
+	c, err := Precompute(pp, repr, CacheOptions{})
+	assert.Nil(t, err)
+	assert.True(t, Vfy(pp, com, 1, c.Qry(1)))
+	assert.Equal(t, 0, c.Stats().NonMembers)
+
+	// verifier params can't precompute answers
+	_, err = Precompute(&PubVerPar{h: pp.h}, repr, CacheOptions{})
+	assert.ErrorIs(t, err, ErrNoProverKey)
+}
+
+func BenchmarkProofCache(b *testing.B) {
+	pp, repr, _, xs := benchmarkRepr(b, 2)
+	b.Run("Qry", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Qry(pp, repr, xs[i%len(xs)])
+		}
+	})
+	c, err := Precompute(pp, repr, CacheOptions{MaxNonMemberBytes: 1 << 24})
+	if err != nil {
+		b.Fatal(err)
+	}
+	c.Warmup(slices.Values(xs))
+	b.Run("Cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c.QryBinary(xs[i%len(xs)])
+		}
+	})
+}
+
+func TestMembershipSources(t *testing.T) {
+	pp := Gen()
+	values := map[uint64]bool{0: true, 3: true, 9: true, 14: true, 15: true}
+	members := []uint64{0, 3, 9, 14, 15}
+	_, com := Rep(pp, NewEnumSet(values, 16))
+
+	sorted, err := NewSortedSet(members, 16)
+	assert.Nil(t, err)
+
+	ch := make(chan uint64)
+	go func() {
+		for _, x := range []uint64{14, 3, 15, 0, 9, 3} {
+			ch <- x
+		}
+		close(ch)
+	}()
+	fromChannel, err := ReadChannel(ch, 16)
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "members.txt")
+	os.WriteFile(path, []byte("# members\n0\n3\n\n9\n14\n15\n"), 0o644)
+	file := NewFileSet(path, 16)
+
+	// every source commits to the same set
+	for _, src := range []MembershipSource{sorted, fromChannel, file} {
+		repr, com2 := Rep(pp, src)
+		assert.True(t, com.Equals(com2))
+		for x := uint64(0); x < 16; x++ {
+			assert.Equal(t, values[x], src.In(x), "membership of %d", x)
+			a := Qry(pp, repr, x)
+			assert.Equal(t, values[x], a.answer, "answer for %d", x)
+			assert.True(t, Vfy(pp, com, x, a), "v should be true.")
+		}
+	}
+
+	// every iteration of a file has an error of its own, so iterations can run concurrently
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scanned, scanErr := file.Scan()
+			assert.Equal(t, members, slices.Collect(scanned))
+			assert.Nil(t, scanErr())
+		}()
+	}
+	wg.Wait()
+	scanned, scanErr := NewFileSet(filepath.Join(t.TempDir(), "missing.txt"), 16).Scan()
+	assert.Empty(t, slices.Collect(scanned))
+	assert.ErrorIs(t, scanErr(), os.ErrNotExist)
+
+	// invalid members are rejected
+	_, err = NewSortedSet([]uint64{3, 0}, 16)
+	assert.ErrorIs(t, err, ErrUnsortedMembers)
+	_, err = NewSortedSet([]uint64{3, 16}, 16)
+	assert.NotNil(t, err)
+
+	os.WriteFile(path, []byte("0\n9\n3\n"), 0o644)
+	_, _, err = RepWithOptions(pp, file, TreeOptions{})
+	assert.ErrorIs(t, err, ErrUnsortedMembers)
+	repr, _ := Rep(pp, file)
+	assert.Nil(t, repr)
+
+	os.WriteFile(path, []byte("0\nthree\n"), 0o644)
+	_, _, err = RepWithOptions(pp, file, TreeOptions{})
+	assert.ErrorContains(t, err, "members.txt:2")
+
+	_, _, err = RepWithOptions(pp, NewFileSet(filepath.Join(t.TempDir(), "missing.txt"), 16), TreeOptions{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// Fills a bitmap set and an EnumSet with the same random members: dense chunks, sparse chunks and isolated values.
+func randomSets(max uint64) (*BitmapSet, *EnumSet) {
+	b := NewBitmapSet(max)
+	es := NewEnumSet(make(map[uint64]bool), max)
+	add := func(x uint64) {
+		b.Add(x)
+		es.Add(x)
+	}
+	for x := uint64(0); x < 1<<16; x++ {
+		if rand.Float64() < 0.7 {
+			add(x)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		add(1<<16 + uint64(rand.Intn(1<<16)))
+		add(uint64(rand.Int63n(int64(max))))
+	}
+	return b, es
+}
+
+func TestBitmapSet(t *testing.T) {
+	max := uint64(1 << 40)
+	b, es := randomSets(max)
+
+	members := slices.Collect(es.Members())
+	assert.Equal(t, members, slices.Collect(b.Iterate()))
+	assert.Equal(t, uint64(len(members)), b.Len())
+	for i := 0; i < 10000; i++ {
+		x := uint64(rand.Int63n(1 << 17))
+		assert.Equal(t, es.In(x), b.In(x), "membership of %d", x)
+	}
+	assert.False(t, b.In(max))
+	b.Add(max)
+	assert.False(t, b.In(max))
+
+	// removing members shrinks dense containers back to arrays
+	c := b.Clone()
+	for x := uint64(0); x < 1<<16; x++ {
+		if x%3 != 0 {
+			c.Remove(x)
+			es.Remove(x)
+		}
+	}
+	assert.Equal(t, slices.Collect(es.Members()), slices.Collect(c.Iterate()))
+	assert.Equal(t, uint64(len(members)), b.Len(), "the clone is independent")
+
+	// encoding
+	data, err := b.MarshalBinary()
+	assert.Nil(t, err)
+	var d BitmapSet
+	assert.Nil(t, d.UnmarshalBinary(data))
+	assert.Equal(t, members, slices.Collect(d.Iterate()))
+	assert.Equal(t, max, d.Max())
+	assert.ErrorIs(t, d.UnmarshalBinary(data[:len(data)-1]), ErrMalformed)
+	small, _ := NewBitmapSetFrom(NewEnumSet(map[uint64]bool{3: true}, 4)).MarshalBinary()
+	small[1] = 3 // the member is no longer below the maximum value
+	assert.ErrorIs(t, d.UnmarshalBinary(small), ErrMalformed)
+
+	// commits to the same set as the EnumSet
+	pp := Gen()
+	values := map[uint64]bool{0: true, 3: true, 9: true, 14: true, 15: true}
+	_, com := Rep(pp, NewEnumSet(values, 16))
+	repr, com2 := Rep(pp, NewBitmapSetFrom(NewEnumSet(values, 16)))
+	assert.True(t, com.Equals(com2))
+	for x := uint64(0); x < 16; x++ {
+		assert.Equal(t, values[x], Qry(pp, repr, x).answer)
+	}
+}
+
+func TestBitmapAlgebra(t *testing.T) {
+	a, ea := randomSets(1 << 20)
+	b, eb := randomSets(1 << 18)
+
+	union, inter, diff := map[uint64]bool{}, map[uint64]bool{}, map[uint64]bool{}
+	for x := range ea.Members() {
+		union[x] = true
+		if eb.In(x) {
+			inter[x] = true
+		} else {
+			diff[x] = true
+		}
+	}
+	for x := range eb.Members() {
+		union[x] = true
+	}
+
+	for _, c := range []struct {
+		got  *BitmapSet
+		want *EnumSet
+	}{
+		{a.Union(b), NewEnumSet(union, 1<<20)},
+		{a.Intersect(b), NewEnumSet(inter, 1<<18)},
+		{a.Difference(b), NewEnumSet(diff, 1<<20)},
+		{b.Union(a), NewEnumSet(union, 1<<20)},
+		{b.Intersect(a), NewEnumSet(inter, 1<<18)},
+	} {
+		assert.Equal(t, c.want.Max(), c.got.Max())
+		assert.Equal(t, slices.Collect(c.want.Members()), slices.Collect(c.got.Iterate()))
+		assert.Equal(t, uint64(len(slices.Collect(c.want.Members()))), c.got.Len())
+	}
+
+	// the operands are left untouched
+	assert.Equal(t, slices.Collect(ea.Members()), slices.Collect(a.Iterate()))
+	assert.Equal(t, uint64(0), a.Difference(a).Len())
+}
+
+func BenchmarkSetConstruction(b *testing.B) {
+	const max = 1 << 20
+	b.Run("map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			es := NewEnumSet(make(map[uint64]bool), max)
+			for x := uint64(0); x < max; x += 2 {
+				es.Add(x)
+			}
+		}
+	})
+	b.Run("bitmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bs := NewBitmapSet(max)
+			for x := uint64(0); x < max; x += 2 {
+				bs.Add(x)
+			}
+		}
+	})
+}
+
+func BenchmarkSetIn(b *testing.B) {
+	const max = 1 << 20
+	es := NewEnumSet(make(map[uint64]bool), max)
+	bs := NewBitmapSet(max)
+	for x := uint64(0); x < max; x += 2 {
+		es.Add(x)
+		bs.Add(x)
+	}
+	b.Run("map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			es.In(uint64(i) % max)
+		}
+	})
+	b.Run("bitmap", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bs.In(uint64(i) % max)
+		}
+	})
+}
+
+func TestLoaders(t *testing.T) {
+	want := []uint64{3, 10, 42}
+	opts := LoadOptions{Max: 64}
+	for _, c := range []struct {
+		read func(io.Reader, LoadOptions) (*BitmapSet, error)
+		data string
+		opts LoadOptions
+	}{
+		{ReadLines, "# members\n42\n\n3\n10\n3\n", opts},
+		{ReadCSV, "name,id\nalice,42\nbob,3\ncarol,\ndave,10\n", LoadOptions{Max: 64, Column: "id"}},
+		{ReadCSV, "id\n42\n3\n10\n", opts},
+		{ReadJSON, "[42, 3,\n 10]", opts},
+	} {
+		set, err := c.read(strings.NewReader(c.data), c.opts)
+		assert.Nil(t, err)
+		assert.Equal(t, want, slices.Collect(set.Members()))
+	}
+
+	// errors name the line
+	for _, c := range []struct {
+		read func(io.Reader, LoadOptions) (*BitmapSet, error)
+		data string
+		line int
+	}{
+		{ReadLines, "1\n2\n64\n", 3},
+		{ReadLines, "1\nten\n", 2},
+		{ReadCSV, "id\n1\n\"x\"\n", 3},
+		{ReadJSON, "[1,\n2,\n99]", 3},
+		{ReadJSON, "[1,\n\"2\"]", 2},
+	} {
+		_, err := c.read(strings.NewReader(c.data), opts)
+		var le *LineError
+		assert.ErrorAs(t, err, &le, "%q", c.data)
+		if le != nil {
+			assert.Equal(t, c.line, le.Line, "%q", c.data)
+		}
+	}
+	_, err := ReadCSV(strings.NewReader("name\nalice\n"), LoadOptions{Max: 64, Column: "id"})
+	assert.ErrorContains(t, err, `no column "id"`)
+
+	// string keys are hashed into the universe
+	keys, err := ReadJSON(strings.NewReader(`["alice@example.com", "bob@example.com"]`), LoadOptions{Max: math.MaxUint64, Hashed: true})
+	assert.Nil(t, err)
+	assert.True(t, keys.In(HashKey("alice@example.com", math.MaxUint64)))
+	assert.Equal(t, uint64(2), keys.Len())
+
+	// repeated keys are one member, distinct keys hashing to the same member are an error
+	hashed := LoadOptions{Max: 4, Hashed: true}
+	keys, err = ReadLines(strings.NewReader("alice\nalice\n"), hashed)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), keys.Len())
+	_, err = ReadLines(strings.NewReader("a\nb\nc\nd\ne\n"), hashed)
+	var le *LineError
+	assert.ErrorAs(t, err, &le)
+	assert.ErrorIs(t, err, ErrKeysCollide)
+	_, err = ReadCSV(strings.NewReader("key\na\nb\nc\nd\ne\n"), hashed)
+	assert.ErrorIs(t, err, ErrKeysCollide)
+	_, err = ReadJSON(strings.NewReader(`["a", "b", "c", "d", "e"]`), hashed)
+	assert.ErrorIs(t, err, ErrKeysCollide)
+
+	// exporters write what the loaders read, and the result feeds Rep directly
+	dir := t.TempDir()
+	set, _ := ReadLines(strings.NewReader("42\n3\n10\n"), opts)
+	for name, write := range map[string]func(io.Writer) error{
+		"members.txt":  func(w io.Writer) error { return WriteLines(w, set) },
+		"members.csv":  func(w io.Writer) error { return WriteCSV(w, set, "id") },
+		"members.json": func(w io.Writer) error { return WriteJSON(w, set) },
+	} {
+		var buf bytes.Buffer
+		assert.Nil(t, write(&buf))
+		os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o644)
+
+		loaded, err := LoadFile(filepath.Join(dir, name), opts)
+		assert.Nil(t, err, name)
+		assert.Equal(t, want, slices.Collect(loaded.Members()), name)
+
+		pp := Gen()
+		repr, com := Rep(pp, loaded)
+		assert.True(t, Vfy(pp, com, 42, Qry(pp, repr, 42)))
+		assert.True(t, Qry(pp, repr, 42).answer)
+	}
+
+	os.WriteFile(filepath.Join(dir, "bad.json"), []byte("[1,\n2,\n-3]"), 0o644)
+	_, err = LoadFile(filepath.Join(dir, "bad.json"), opts)
+	assert.ErrorContains(t, err, "bad.json:3")
+}
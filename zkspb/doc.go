@@ -0,0 +1,4 @@
+// Package zkspb holds the gRPC definition of the ZKS prover service (zks.proto) and its generated Go stubs.
+package zkspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative zks.proto
@@ -0,0 +1,441 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: zks.proto
+
+package zkspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetCommitmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommitmentRequest) Reset() {
+	*x = GetCommitmentRequest{}
+	mi := &file_zks_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommitmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommitmentRequest) ProtoMessage() {}
+
+func (x *GetCommitmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zks_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommitmentRequest.ProtoReflect.Descriptor instead.
+func (*GetCommitmentRequest) Descriptor() ([]byte, []int) {
+	return file_zks_proto_rawDescGZIP(), []int{0}
+}
+
+// A commitment published by the prover. The epoch increases with every new set.
+type Commitment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Epoch         uint64                 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Commitment    []byte                 `protobuf:"bytes,2,opt,name=commitment,proto3" json:"commitment,omitempty"`
+	Params        []byte                 `protobuf:"bytes,3,opt,name=params,proto3" json:"params,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Commitment) Reset() {
+	*x = Commitment{}
+	mi := &file_zks_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Commitment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Commitment) ProtoMessage() {}
+
+func (x *Commitment) ProtoReflect() protoreflect.Message {
+	mi := &file_zks_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Commitment.ProtoReflect.Descriptor instead.
+func (*Commitment) Descriptor() ([]byte, []int) {
+	return file_zks_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Commitment) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *Commitment) GetCommitment() []byte {
+	if x != nil {
+		return x.Commitment
+	}
+	return nil
+}
+
+func (x *Commitment) GetParams() []byte {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type QueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             uint64                 `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryRequest) Reset() {
+	*x = QueryRequest{}
+	mi := &file_zks_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRequest) ProtoMessage() {}
+
+func (x *QueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zks_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRequest.ProtoReflect.Descriptor instead.
+func (*QueryRequest) Descriptor() ([]byte, []int) {
+	return file_zks_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *QueryRequest) GetX() uint64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+type QueryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Epoch         uint64                 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Answer        []byte                 `protobuf:"bytes,2,opt,name=answer,proto3" json:"answer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryResponse) Reset() {
+	*x = QueryResponse{}
+	mi := &file_zks_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryResponse) ProtoMessage() {}
+
+func (x *QueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zks_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryResponse.ProtoReflect.Descriptor instead.
+func (*QueryResponse) Descriptor() ([]byte, []int) {
+	return file_zks_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *QueryResponse) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *QueryResponse) GetAnswer() []byte {
+	if x != nil {
+		return x.Answer
+	}
+	return nil
+}
+
+type QueryBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Xs            []uint64               `protobuf:"varint,1,rep,packed,name=xs,proto3" json:"xs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryBatchRequest) Reset() {
+	*x = QueryBatchRequest{}
+	mi := &file_zks_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryBatchRequest) ProtoMessage() {}
+
+func (x *QueryBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zks_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryBatchRequest.ProtoReflect.Descriptor instead.
+func (*QueryBatchRequest) Descriptor() ([]byte, []int) {
+	return file_zks_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *QueryBatchRequest) GetXs() []uint64 {
+	if x != nil {
+		return x.Xs
+	}
+	return nil
+}
+
+// Answers in the order of the request, all under the commitment of the epoch.
+type QueryBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Epoch         uint64                 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Answers       [][]byte               `protobuf:"bytes,2,rep,name=answers,proto3" json:"answers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryBatchResponse) Reset() {
+	*x = QueryBatchResponse{}
+	mi := &file_zks_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryBatchResponse) ProtoMessage() {}
+
+func (x *QueryBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zks_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryBatchResponse.ProtoReflect.Descriptor instead.
+func (*QueryBatchResponse) Descriptor() ([]byte, []int) {
+	return file_zks_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *QueryBatchResponse) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *QueryBatchResponse) GetAnswers() [][]byte {
+	if x != nil {
+		return x.Answers
+	}
+	return nil
+}
+
+type WatchCommitmentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchCommitmentsRequest) Reset() {
+	*x = WatchCommitmentsRequest{}
+	mi := &file_zks_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchCommitmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchCommitmentsRequest) ProtoMessage() {}
+
+func (x *WatchCommitmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zks_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchCommitmentsRequest.ProtoReflect.Descriptor instead.
+func (*WatchCommitmentsRequest) Descriptor() ([]byte, []int) {
+	return file_zks_proto_rawDescGZIP(), []int{6}
+}
+
+var File_zks_proto protoreflect.FileDescriptor
+
+const file_zks_proto_rawDesc = "" +
+	"\n" +
+	"\tzks.proto\x12\x06zks.v1\"\x16\n" +
+	"\x14GetCommitmentRequest\"Z\n" +
+	"\n" +
+	"Commitment\x12\x14\n" +
+	"\x05epoch\x18\x01 \x01(\x04R\x05epoch\x12\x1e\n" +
+	"\n" +
+	"commitment\x18\x02 \x01(\fR\n" +
+	"commitment\x12\x16\n" +
+	"\x06params\x18\x03 \x01(\fR\x06params\"\x1c\n" +
+	"\fQueryRequest\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x04R\x01x\"=\n" +
+	"\rQueryResponse\x12\x14\n" +
+	"\x05epoch\x18\x01 \x01(\x04R\x05epoch\x12\x16\n" +
+	"\x06answer\x18\x02 \x01(\fR\x06answer\"#\n" +
+	"\x11QueryBatchRequest\x12\x0e\n" +
+	"\x02xs\x18\x01 \x03(\x04R\x02xs\"D\n" +
+	"\x12QueryBatchResponse\x12\x14\n" +
+	"\x05epoch\x18\x01 \x01(\x04R\x05epoch\x12\x18\n" +
+	"\aanswers\x18\x02 \x03(\fR\aanswers\"\x19\n" +
+	"\x17WatchCommitmentsRequest2\x91\x02\n" +
+	"\x06Prover\x12A\n" +
+	"\rGetCommitment\x12\x1c.zks.v1.GetCommitmentRequest\x1a\x12.zks.v1.Commitment\x124\n" +
+	"\x05Query\x12\x14.zks.v1.QueryRequest\x1a\x15.zks.v1.QueryResponse\x12C\n" +
+	"\n" +
+	"QueryBatch\x12\x19.zks.v1.QueryBatchRequest\x1a\x1a.zks.v1.QueryBatchResponse\x12I\n" +
+	"\x10WatchCommitments\x12\x1f.zks.v1.WatchCommitmentsRequest\x1a\x12.zks.v1.Commitment0\x01B Z\x1egithub.com/smarky7cd/ZKS/zkspbb\x06proto3"
+
+var (
+	file_zks_proto_rawDescOnce sync.Once
+	file_zks_proto_rawDescData []byte
+)
+
+func file_zks_proto_rawDescGZIP() []byte {
+	file_zks_proto_rawDescOnce.Do(func() {
+		file_zks_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_zks_proto_rawDesc), len(file_zks_proto_rawDesc)))
+	})
+	return file_zks_proto_rawDescData
+}
+
+var file_zks_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_zks_proto_goTypes = []any{
+	(*GetCommitmentRequest)(nil),    // 0: zks.v1.GetCommitmentRequest
+	(*Commitment)(nil),              // 1: zks.v1.Commitment
+	(*QueryRequest)(nil),            // 2: zks.v1.QueryRequest
+	(*QueryResponse)(nil),           // 3: zks.v1.QueryResponse
+	(*QueryBatchRequest)(nil),       // 4: zks.v1.QueryBatchRequest
+	(*QueryBatchResponse)(nil),      // 5: zks.v1.QueryBatchResponse
+	(*WatchCommitmentsRequest)(nil), // 6: zks.v1.WatchCommitmentsRequest
+}
+var file_zks_proto_depIdxs = []int32{
+	0, // 0: zks.v1.Prover.GetCommitment:input_type -> zks.v1.GetCommitmentRequest
+	2, // 1: zks.v1.Prover.Query:input_type -> zks.v1.QueryRequest
+	4, // 2: zks.v1.Prover.QueryBatch:input_type -> zks.v1.QueryBatchRequest
+	6, // 3: zks.v1.Prover.WatchCommitments:input_type -> zks.v1.WatchCommitmentsRequest
+	1, // 4: zks.v1.Prover.GetCommitment:output_type -> zks.v1.Commitment
+	3, // 5: zks.v1.Prover.Query:output_type -> zks.v1.QueryResponse
+	5, // 6: zks.v1.Prover.QueryBatch:output_type -> zks.v1.QueryBatchResponse
+	1, // 7: zks.v1.Prover.WatchCommitments:output_type -> zks.v1.Commitment
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_zks_proto_init() }
+func file_zks_proto_init() {
+	if File_zks_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_zks_proto_rawDesc), len(file_zks_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_zks_proto_goTypes,
+		DependencyIndexes: file_zks_proto_depIdxs,
+		MessageInfos:      file_zks_proto_msgTypes,
+	}.Build()
+	File_zks_proto = out.File
+	file_zks_proto_goTypes = nil
+	file_zks_proto_depIdxs = nil
+}
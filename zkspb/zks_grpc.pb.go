@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: zks.proto
+
+package zkspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Prover_GetCommitment_FullMethodName    = "/zks.v1.Prover/GetCommitment"
+	Prover_Query_FullMethodName            = "/zks.v1.Prover/Query"
+	Prover_QueryBatch_FullMethodName       = "/zks.v1.Prover/QueryBatch"
+	Prover_WatchCommitments_FullMethodName = "/zks.v1.Prover/WatchCommitments"
+)
+
+// ProverClient is the client API for Prover service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// A ZKS prover. Commitments, verifier params and answers are carried in the
+// binary encodings of the zks package (MarshalBinary).
+type ProverClient interface {
+	// Returns the current commitment and the verifier params.
+	GetCommitment(ctx context.Context, in *GetCommitmentRequest, opts ...grpc.CallOption) (*Commitment, error)
+	// Answers a query on a single element.
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	// Answers queries on several elements against the same commitment.
+	QueryBatch(ctx context.Context, in *QueryBatchRequest, opts ...grpc.CallOption) (*QueryBatchResponse, error)
+	// Streams the current commitment, then every commitment published after it.
+	WatchCommitments(ctx context.Context, in *WatchCommitmentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Commitment], error)
+}
+
+type proverClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProverClient(cc grpc.ClientConnInterface) ProverClient {
+	return &proverClient{cc}
+}
+
+func (c *proverClient) GetCommitment(ctx context.Context, in *GetCommitmentRequest, opts ...grpc.CallOption) (*Commitment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Commitment)
+	err := c.cc.Invoke(ctx, Prover_GetCommitment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proverClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, Prover_Query_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proverClient) QueryBatch(ctx context.Context, in *QueryBatchRequest, opts ...grpc.CallOption) (*QueryBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryBatchResponse)
+	err := c.cc.Invoke(ctx, Prover_QueryBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proverClient) WatchCommitments(ctx context.Context, in *WatchCommitmentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Commitment], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Prover_ServiceDesc.Streams[0], Prover_WatchCommitments_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchCommitmentsRequest, Commitment]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Prover_WatchCommitmentsClient = grpc.ServerStreamingClient[Commitment]
+
+// ProverServer is the server API for Prover service.
+// All implementations must embed UnimplementedProverServer
+// for forward compatibility.
+//
+// A ZKS prover. Commitments, verifier params and answers are carried in the
+// binary encodings of the zks package (MarshalBinary).
+type ProverServer interface {
+	// Returns the current commitment and the verifier params.
+	GetCommitment(context.Context, *GetCommitmentRequest) (*Commitment, error)
+	// Answers a query on a single element.
+	Query(context.Context, *QueryRequest) (*QueryResponse, error)
+	// Answers queries on several elements against the same commitment.
+	QueryBatch(context.Context, *QueryBatchRequest) (*QueryBatchResponse, error)
+	// Streams the current commitment, then every commitment published after it.
+	WatchCommitments(*WatchCommitmentsRequest, grpc.ServerStreamingServer[Commitment]) error
+	mustEmbedUnimplementedProverServer()
+}
+
+// UnimplementedProverServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProverServer struct{}
+
+func (UnimplementedProverServer) GetCommitment(context.Context, *GetCommitmentRequest) (*Commitment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommitment not implemented")
+}
+func (UnimplementedProverServer) Query(context.Context, *QueryRequest) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedProverServer) QueryBatch(context.Context, *QueryBatchRequest) (*QueryBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryBatch not implemented")
+}
+func (UnimplementedProverServer) WatchCommitments(*WatchCommitmentsRequest, grpc.ServerStreamingServer[Commitment]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchCommitments not implemented")
+}
+func (UnimplementedProverServer) mustEmbedUnimplementedProverServer() {}
+func (UnimplementedProverServer) testEmbeddedByValue()                {}
+
+// UnsafeProverServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProverServer will
+// result in compilation errors.
+type UnsafeProverServer interface {
+	mustEmbedUnimplementedProverServer()
+}
+
+func RegisterProverServer(s grpc.ServiceRegistrar, srv ProverServer) {
+	// If the following call pancis, it indicates UnimplementedProverServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Prover_ServiceDesc, srv)
+}
+
+func _Prover_GetCommitment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCommitmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProverServer).GetCommitment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Prover_GetCommitment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProverServer).GetCommitment(ctx, req.(*GetCommitmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prover_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProverServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Prover_Query_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProverServer).Query(ctx, req.(*QueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prover_QueryBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProverServer).QueryBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Prover_QueryBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProverServer).QueryBatch(ctx, req.(*QueryBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prover_WatchCommitments_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchCommitmentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProverServer).WatchCommitments(m, &grpc.GenericServerStream[WatchCommitmentsRequest, Commitment]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Prover_WatchCommitmentsServer = grpc.ServerStreamingServer[Commitment]
+
+// Prover_ServiceDesc is the grpc.ServiceDesc for Prover service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Prover_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "zks.v1.Prover",
+	HandlerType: (*ProverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCommitment",
+			Handler:    _Prover_GetCommitment_Handler,
+		},
+		{
+			MethodName: "Query",
+			Handler:    _Prover_Query_Handler,
+		},
+		{
+			MethodName: "QueryBatch",
+			Handler:    _Prover_QueryBatch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchCommitments",
+			Handler:       _Prover_WatchCommitments_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "zks.proto",
+}